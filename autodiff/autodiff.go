@@ -0,0 +1,226 @@
+// Package autodiff computes exact gradients of scalar functions by
+// reverse-mode automatic differentiation, so log densities written once can
+// drive gradient-based samplers and optimizers.
+package autodiff
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mathext"
+)
+
+// Tape records the operations of a computation so that gradients can be
+// propagated backwards through it. A tape is not safe for concurrent use.
+type Tape struct {
+	nodes []node
+}
+
+// node is one recorded operation with the local partial derivatives of its
+// result with respect to up to two inputs
+type node struct {
+	parents [2]int
+	weights [2]float64
+	arity   int
+}
+
+// Var is a value recorded on a tape
+type Var struct {
+	Value float64
+	tape  *Tape
+	index int
+}
+
+// NewTape creates an empty tape
+func NewTape() *Tape {
+	return &Tape{}
+}
+
+// Reset clears the tape so it can record a new computation
+func (t *Tape) Reset() {
+	t.nodes = t.nodes[:0]
+}
+
+// Var records an independent variable
+func (t *Tape) Var(x float64) Var {
+	return t.push(x, node{})
+}
+
+// Vars records a vector of independent variables
+func (t *Tape) Vars(x []float64) []Var {
+	vars := make([]Var, len(x))
+	for i, v := range x {
+		vars[i] = t.Var(v)
+	}
+	return vars
+}
+
+// Const records a constant. It is stored like a variable; a constant is
+// simply one whose gradient is never requested.
+func (t *Tape) Const(x float64) Var {
+	return t.push(x, node{})
+}
+
+// Gradient returns the derivative of out with respect to each of wrt
+func (t *Tape) Gradient(out Var, wrt []Var) []float64 {
+	adjoints := make([]float64, len(t.nodes))
+	adjoints[out.index] = 1
+	for i := out.index; i >= 0; i-- {
+		adj := adjoints[i]
+		if adj == 0 {
+			continue
+		}
+		n := t.nodes[i]
+		for k := 0; k < n.arity; k++ {
+			adjoints[n.parents[k]] += n.weights[k] * adj
+		}
+	}
+
+	grad := make([]float64, len(wrt))
+	for i, v := range wrt {
+		grad[i] = adjoints[v.index]
+	}
+	return grad
+}
+
+// push records a node and returns its value
+func (t *Tape) push(value float64, n node) Var {
+	t.nodes = append(t.nodes, n)
+	return Var{Value: value, tape: t, index: len(t.nodes) - 1}
+}
+
+// unary records a function of one input with derivative d
+func unary(a Var, value, d float64) Var {
+	return a.tape.push(value, node{parents: [2]int{a.index}, weights: [2]float64{d}, arity: 1})
+}
+
+// binary records a function of two inputs with partial derivatives da and db
+func binary(a, b Var, value, da, db float64) Var {
+	return a.tape.push(value, node{
+		parents: [2]int{a.index, b.index},
+		weights: [2]float64{da, db},
+		arity:   2,
+	})
+}
+
+// Custom records a function that autodiff does not provide, given its value
+// and derivative at a
+func Custom(a Var, value, derivative float64) Var {
+	return unary(a, value, derivative)
+}
+
+// Add returns a + b
+func (a Var) Add(b Var) Var {
+	return binary(a, b, a.Value+b.Value, 1, 1)
+}
+
+// Sub returns a - b
+func (a Var) Sub(b Var) Var {
+	return binary(a, b, a.Value-b.Value, 1, -1)
+}
+
+// Mul returns a * b
+func (a Var) Mul(b Var) Var {
+	return binary(a, b, a.Value*b.Value, b.Value, a.Value)
+}
+
+// Div returns a / b
+func (a Var) Div(b Var) Var {
+	return binary(a, b, a.Value/b.Value, 1/b.Value, -a.Value/(b.Value*b.Value))
+}
+
+// Neg returns -a
+func (a Var) Neg() Var {
+	return unary(a, -a.Value, -1)
+}
+
+// Shift returns a + c for a constant c
+func (a Var) Shift(c float64) Var {
+	return unary(a, a.Value+c, 1)
+}
+
+// Scale returns c * a for a constant c
+func (a Var) Scale(c float64) Var {
+	return unary(a, c*a.Value, c)
+}
+
+// Sum returns the sum of vars, which must not be empty
+func Sum(vars ...Var) Var {
+	total := vars[0]
+	for _, v := range vars[1:] {
+		total = total.Add(v)
+	}
+	return total
+}
+
+// Square returns a²
+func Square(a Var) Var {
+	return unary(a, a.Value*a.Value, 2*a.Value)
+}
+
+// Sqrt returns √a
+func Sqrt(a Var) Var {
+	s := math.Sqrt(a.Value)
+	return unary(a, s, 0.5/s)
+}
+
+// Pow returns a^c for a constant exponent c
+func Pow(a Var, c float64) Var {
+	if c == 0 {
+		// a⁰ is constant; c·a⁻¹ would be NaN at a = 0
+		return unary(a, 1, 0)
+	}
+	return unary(a, math.Pow(a.Value, c), c*math.Pow(a.Value, c-1))
+}
+
+// Exp returns eᵃ
+func Exp(a Var) Var {
+	e := math.Exp(a.Value)
+	return unary(a, e, e)
+}
+
+// Log returns the natural logarithm of a
+func Log(a Var) Var {
+	return unary(a, math.Log(a.Value), 1/a.Value)
+}
+
+// Log1p returns log(1 + a)
+func Log1p(a Var) Var {
+	return unary(a, math.Log1p(a.Value), 1/(1+a.Value))
+}
+
+// Lgamma returns log Γ(a), whose derivative is the digamma function
+func Lgamma(a Var) Var {
+	lg, _ := math.Lgamma(a.Value)
+	return unary(a, lg, mathext.Digamma(a.Value))
+}
+
+// Lbeta returns log B(a, b)
+func Lbeta(a, b Var) Var {
+	return Lgamma(a).Add(Lgamma(b)).Sub(Lgamma(a.Add(b)))
+}
+
+// Logistic returns 1 / (1 + e⁻ᵃ)
+func Logistic(a Var) Var {
+	s := logistic(a.Value)
+	return unary(a, s, s*(1-s))
+}
+
+// logistic evaluates 1 / (1 + e⁻ˣ) without overflow
+func logistic(x float64) float64 {
+	if x >= 0 {
+		return 1 / (1 + math.Exp(-x))
+	}
+	e := math.Exp(x)
+	return e / (1 + e)
+}
+
+// Softplus returns log(1 + eᵃ) without overflow
+func Softplus(a Var) Var {
+	var value float64
+	if a.Value > 0 {
+		value = a.Value + math.Log1p(math.Exp(-a.Value))
+	} else {
+		value = math.Log1p(math.Exp(a.Value))
+	}
+	return unary(a, value, logistic(a.Value))
+}
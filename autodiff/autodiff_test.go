@@ -0,0 +1,187 @@
+package autodiff
+
+import (
+	"math"
+	"testing"
+)
+
+// finiteDifference returns the central difference gradient of f at x
+func finiteDifference(f func(x []float64) float64, x []float64) []float64 {
+	grad := make([]float64, len(x))
+	shifted := make([]float64, len(x))
+	for i := range x {
+		h := 1e-6 * math.Max(1, math.Abs(x[i]))
+		copy(shifted, x)
+		shifted[i] = x[i] + h
+		up := f(shifted)
+		shifted[i] = x[i] - h
+		down := f(shifted)
+		grad[i] = (up - down) / (2 * h)
+	}
+	return grad
+}
+
+// checkGradient compares the gradient of f at x with central differences
+func checkGradient(t *testing.T, name string, f Func, x []float64) {
+	t.Helper()
+	value, grad := Gradient(f, x)
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		t.Errorf("%s: value %v at %v", name, value, x)
+		return
+	}
+	numeric := finiteDifference(func(x []float64) float64 {
+		v, _ := Gradient(f, x)
+		return v
+	}, x)
+	for i := range x {
+		if math.Abs(grad[i]-numeric[i]) > 1e-5*math.Max(1, math.Abs(numeric[i])) {
+			t.Errorf("%s: d/dx%d = %.8g, finite difference %.8g at %v", name, i, grad[i], numeric[i], x)
+		}
+	}
+}
+
+func TestOperationGradients(t *testing.T) {
+	tests := []struct {
+		name string
+		f    Func
+		at   [][]float64
+	}{
+		{"Add", func(_ *Tape, x []Var) Var { return x[0].Add(x[1]) }, [][]float64{{1.5, -2}}},
+		{"Sub", func(_ *Tape, x []Var) Var { return x[0].Sub(x[1]) }, [][]float64{{1.5, -2}}},
+		{"Mul", func(_ *Tape, x []Var) Var { return x[0].Mul(x[1]) }, [][]float64{{1.5, -2}, {0, 3}}},
+		{"Div", func(_ *Tape, x []Var) Var { return x[0].Div(x[1]) }, [][]float64{{1.5, -2}, {0, 0.3}}},
+		{"Neg", func(_ *Tape, x []Var) Var { return x[0].Neg() }, [][]float64{{0.7}}},
+		{"Shift", func(_ *Tape, x []Var) Var { return x[0].Shift(3) }, [][]float64{{0.7}}},
+		{"Scale", func(_ *Tape, x []Var) Var { return x[0].Scale(-2.5) }, [][]float64{{0.7}}},
+		{"Sum", func(_ *Tape, x []Var) Var { return Sum(x...) }, [][]float64{{1, 2, 3}}},
+		{"Square", func(_ *Tape, x []Var) Var { return Square(x[0]) }, [][]float64{{-1.3}, {0}}},
+		{"Sqrt", func(_ *Tape, x []Var) Var { return Sqrt(x[0]) }, [][]float64{{0.01}, {4}}},
+		{"Pow", func(_ *Tape, x []Var) Var { return Pow(x[0], 2.5) }, [][]float64{{0.3}, {2}}},
+		{"Pow negative exponent", func(_ *Tape, x []Var) Var { return Pow(x[0], -1.5) }, [][]float64{{0.3}, {2}}},
+		{"Exp", func(_ *Tape, x []Var) Var { return Exp(x[0]) }, [][]float64{{-3}, {0}, {2}}},
+		{"Log", func(_ *Tape, x []Var) Var { return Log(x[0]) }, [][]float64{{1e-3}, {5}}},
+		{"Log1p", func(_ *Tape, x []Var) Var { return Log1p(x[0]) }, [][]float64{{-0.5}, {0}, {3}}},
+		{"Lgamma", func(_ *Tape, x []Var) Var { return Lgamma(x[0]) }, [][]float64{{0.01}, {0.5}, {1}, {7.5}, {150}}},
+		{"Lbeta", func(_ *Tape, x []Var) Var { return Lbeta(x[0], x[1]) }, [][]float64{{0.5, 3}, {20, 80}}},
+		{"Logistic", func(_ *Tape, x []Var) Var { return Logistic(x[0]) }, [][]float64{{-30}, {0}, {2}}},
+		{"Softplus", func(_ *Tape, x []Var) Var { return Softplus(x[0]) }, [][]float64{{-30}, {0}, {30}}},
+		{"Custom", func(_ *Tape, x []Var) Var {
+			return Custom(x[0], math.Sin(x[0].Value), math.Cos(x[0].Value))
+		}, [][]float64{{0.4}}},
+		{"composition", func(t *Tape, x []Var) Var {
+			return Log(Square(x[0]).Add(t.Const(1))).Mul(Exp(x[1].Scale(0.5))).Div(Sqrt(x[2]))
+		}, [][]float64{{0.8, -0.3, 2}}},
+		{"reused variable", func(_ *Tape, x []Var) Var {
+			return x[0].Mul(x[0]).Mul(x[0]).Sub(x[0])
+		}, [][]float64{{1.7}}},
+	}
+	for _, tt := range tests {
+		for _, x := range tt.at {
+			checkGradient(t, tt.name, tt.f, x)
+		}
+	}
+}
+
+func TestConstantHasNoGradient(t *testing.T) {
+	tape := NewTape()
+	x := tape.Var(2)
+	c := tape.Const(3)
+	grad := tape.Gradient(x.Mul(c), []Var{x, c})
+	if grad[0] != 3 {
+		t.Errorf("d/dx = %v, want 3", grad[0])
+	}
+	unused := tape.Var(5)
+	if got := tape.Gradient(x.Mul(c), []Var{unused})[0]; got != 0 {
+		t.Errorf("gradient of an unused variable = %v, want 0", got)
+	}
+}
+
+func TestTapeReset(t *testing.T) {
+	tape := NewTape()
+	for _, v := range []float64{1, 2, 3} {
+		tape.Reset()
+		x := tape.Var(v)
+		out := Square(x)
+		if got := tape.Gradient(out, []Var{x})[0]; got != 2*v {
+			t.Errorf("after Reset: d/dx x² at %v = %v, want %v", v, got, 2*v)
+		}
+		if len(tape.nodes) != 2 {
+			t.Errorf("tape holds %d nodes after Reset, want 2", len(tape.nodes))
+		}
+	}
+}
+
+func TestLgammaSmallArguments(t *testing.T) {
+	const eulerGamma = 0.5772156649015329
+	for _, x := range []float64{1e-8, 1e-5, 1e-3} {
+		tape := NewTape()
+		v := tape.Var(x)
+		out := Lgamma(v)
+		// log Γ(x) ≈ -log x - γx and ψ(x) ≈ -1/x - γ as x → 0
+		if want := -math.Log(x) - eulerGamma*x; math.Abs(out.Value-want) > 1e-5*math.Abs(want) {
+			t.Errorf("Lgamma(%v) = %v, want %v", x, out.Value, want)
+		}
+		if got, want := tape.Gradient(out, []Var{v})[0], -1/x-eulerGamma; math.Abs(got-want) > 1e-5*math.Abs(want) {
+			t.Errorf("digamma(%v) = %v, want %v", x, got, want)
+		}
+	}
+
+	// gonum's digamma is accurate to about 1e-10 at moderate arguments
+	tape := NewTape()
+	one := tape.Var(1)
+	if got := tape.Gradient(Lgamma(one), []Var{one})[0]; math.Abs(got+eulerGamma) > 1e-9 {
+		t.Errorf("digamma(1) = %v, want %v", got, -eulerGamma)
+	}
+}
+
+func TestLog1pNearZero(t *testing.T) {
+	for _, x := range []float64{1e-15, -1e-12, 1e-9} {
+		tape := NewTape()
+		v := tape.Var(x)
+		out := Log1p(v)
+		// log(1 + x) ≈ x - x²/2, which log(1 + x) computed naively loses
+		if want := x - x*x/2; math.Abs(out.Value-want) > 1e-14*math.Abs(want) {
+			t.Errorf("Log1p(%v) = %v, want %v", x, out.Value, want)
+		}
+		if got, want := tape.Gradient(out, []Var{v})[0], 1/(1+x); got != want {
+			t.Errorf("d/dx Log1p(%v) = %v, want %v", x, got, want)
+		}
+	}
+}
+
+func TestPowAtZero(t *testing.T) {
+	tests := []struct {
+		c, value, derivative float64
+	}{
+		{0, 1, 0},
+		{1, 0, 1},
+		{2, 0, 0},
+		{3.5, 0, 0},
+		{0.5, 0, math.Inf(1)},
+	}
+	for _, tt := range tests {
+		tape := NewTape()
+		x := tape.Var(0)
+		out := Pow(x, tt.c)
+		if out.Value != tt.value {
+			t.Errorf("Pow(0, %v) = %v, want %v", tt.c, out.Value, tt.value)
+		}
+		if got := tape.Gradient(out, []Var{x})[0]; got != tt.derivative {
+			t.Errorf("d/dx Pow(x, %v) at 0 = %v, want %v", tt.c, got, tt.derivative)
+		}
+	}
+}
+
+func TestSoftplusAndLogisticDoNotOverflow(t *testing.T) {
+	for _, x := range []float64{-800, 800} {
+		tape := NewTape()
+		v := tape.Var(x)
+		sp, lg := Softplus(v), Logistic(v)
+		if math.IsInf(sp.Value, 0) || math.IsNaN(sp.Value) || math.IsNaN(lg.Value) {
+			t.Errorf("at %v: Softplus = %v, Logistic = %v", x, sp.Value, lg.Value)
+		}
+		if g := tape.Gradient(sp, []Var{v})[0]; math.IsNaN(g) {
+			t.Errorf("d/dx Softplus at %v is NaN", x)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package autodiff
+
+import (
+	"math"
+
+	"github.com/MyVueCodeHub/myvue-bayes/inference"
+)
+
+// The log densities below mirror LogPDF and LogPMF of the corresponding
+// types in the distributions package, but are differentiable in every
+// argument. Support constraints are not checked; transform constrained
+// parameters, e.g. with Exp or Logistic, before passing them in.
+
+// NormalLogPDF returns the log density of Normal(mu, sigma) at x
+func NormalLogPDF(x, mu, sigma Var) Var {
+	z := x.Sub(mu).Div(sigma)
+	return Square(z).Scale(-0.5).Sub(Log(sigma)).Shift(-0.5 * math.Log(2*math.Pi))
+}
+
+// LogNormalLogPDF returns the log density of LogNormal(mu, sigma) at x
+func LogNormalLogPDF(x, mu, sigma Var) Var {
+	logX := Log(x)
+	return NormalLogPDF(logX, mu, sigma).Sub(logX)
+}
+
+// StudentTLogPDF returns the log density of a Student's t distribution with
+// location mu, scale sigma and nu degrees of freedom at x
+func StudentTLogPDF(x, mu, sigma, nu Var) Var {
+	z := x.Sub(mu).Div(sigma)
+	halfNuPlusOne := nu.Shift(1).Scale(0.5)
+	return Lgamma(halfNuPlusOne).
+		Sub(Lgamma(nu.Scale(0.5))).
+		Sub(Log(nu).Scale(0.5)).
+		Sub(Log(sigma)).
+		Sub(halfNuPlusOne.Mul(Log1p(Square(z).Div(nu)))).
+		Shift(-0.5 * math.Log(math.Pi))
+}
+
+// BetaLogPDF returns the log density of Beta(alpha, beta) at x
+func BetaLogPDF(x, alpha, beta Var) Var {
+	one := x.tape.Const(1)
+	return alpha.Shift(-1).Mul(Log(x)).
+		Add(beta.Shift(-1).Mul(Log(one.Sub(x)))).
+		Sub(Lbeta(alpha, beta))
+}
+
+// GammaLogPDF returns the log density of Gamma(shape, rate) at x
+func GammaLogPDF(x, shape, rate Var) Var {
+	return shape.Mul(Log(rate)).
+		Sub(Lgamma(shape)).
+		Add(shape.Shift(-1).Mul(Log(x))).
+		Sub(rate.Mul(x))
+}
+
+// InverseGammaLogPDF returns the log density of InverseGamma(shape, scale) at x
+func InverseGammaLogPDF(x, shape, scale Var) Var {
+	return shape.Mul(Log(scale)).
+		Sub(Lgamma(shape)).
+		Sub(shape.Shift(1).Mul(Log(x))).
+		Sub(scale.Div(x))
+}
+
+// ExponentialLogPDF returns the log density of Exponential(rate) at x
+func ExponentialLogPDF(x, rate Var) Var {
+	return Log(rate).Sub(rate.Mul(x))
+}
+
+// BinomialLogPMF returns the log probability of k successes in n trials with
+// success probability p
+func BinomialLogPMF(k, n int, p Var) Var {
+	one := p.tape.Const(1)
+	return Log(p).Scale(float64(k)).
+		Add(Log(one.Sub(p)).Scale(float64(n - k))).
+		Shift(logChoose(n, k))
+}
+
+// PoissonLogPMF returns the log probability of k events at the given rate
+func PoissonLogPMF(k int, rate Var) Var {
+	lgK, _ := math.Lgamma(float64(k) + 1)
+	return Log(rate).Scale(float64(k)).Sub(rate).Shift(-lgK)
+}
+
+// logChoose returns log(n choose k)
+func logChoose(n, k int) float64 {
+	lgN, _ := math.Lgamma(float64(n) + 1)
+	lgK, _ := math.Lgamma(float64(k) + 1)
+	lgNK, _ := math.Lgamma(float64(n-k) + 1)
+	return lgN - lgK - lgNK
+}
+
+// Func is a scalar function of a parameter vector recorded on a tape
+type Func func(t *Tape, x []Var) Var
+
+// Gradient evaluates f at x and returns its value and gradient
+func Gradient(f Func, x []float64) (float64, []float64) {
+	t := NewTape()
+	vars := t.Vars(x)
+	out := f(t, vars)
+	return out.Value, t.Gradient(out, vars)
+}
+
+// Density adapts a log density written with Vars to the interface of the
+// gradient-based samplers in the inference package
+func Density(f Func) inference.DifferentiableDensity {
+	t := NewTape()
+	return inference.GradientFunc(func(x, grad []float64) float64 {
+		t.Reset()
+		vars := t.Vars(x)
+		out := f(t, vars)
+		copy(grad, t.Gradient(out, vars))
+		return out.Value
+	})
+}
+
+// LogDensity adapts a log density written with Vars to a plain
+// inference.LogDensity, for samplers that need no gradient
+func LogDensity(f Func) inference.LogDensity {
+	t := NewTape()
+	return func(x []float64) float64 {
+		t.Reset()
+		return f(t, t.Vars(x)).Value
+	}
+}
@@ -0,0 +1,119 @@
+package autodiff
+
+import (
+	"math"
+	"testing"
+
+	"github.com/MyVueCodeHub/myvue-bayes/distributions"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+func TestLogDensities(t *testing.T) {
+	tests := []struct {
+		name string
+		f    Func
+		at   []float64
+		want func(x []float64) float64
+	}{
+		{
+			"Normal",
+			func(_ *Tape, x []Var) Var { return NormalLogPDF(x[0], x[1], x[2]) },
+			[]float64{0.7, -0.4, 1.3},
+			func(x []float64) float64 { return distributions.NewNormal(x[1], x[2]).LogPDF(x[0]) },
+		},
+		{
+			"LogNormal",
+			func(_ *Tape, x []Var) Var { return LogNormalLogPDF(x[0], x[1], x[2]) },
+			[]float64{2.1, 0.3, 0.8},
+			func(x []float64) float64 { return distuv.LogNormal{Mu: x[1], Sigma: x[2]}.LogProb(x[0]) },
+		},
+		{
+			"StudentT",
+			func(_ *Tape, x []Var) Var { return StudentTLogPDF(x[0], x[1], x[2], x[3]) },
+			[]float64{1.9, 0.5, 1.2, 4.5},
+			func(x []float64) float64 { return distributions.NewStudentT(x[1], x[2], x[3]).LogPDF(x[0]) },
+		},
+		{
+			"Beta",
+			func(_ *Tape, x []Var) Var { return BetaLogPDF(x[0], x[1], x[2]) },
+			[]float64{0.23, 2.5, 7},
+			func(x []float64) float64 { return distributions.NewBeta(x[1], x[2]).LogPDF(x[0]) },
+		},
+		{
+			"Beta with shapes below one",
+			func(_ *Tape, x []Var) Var { return BetaLogPDF(x[0], x[1], x[2]) },
+			[]float64{0.9, 0.5, 0.5},
+			func(x []float64) float64 { return distributions.NewBeta(x[1], x[2]).LogPDF(x[0]) },
+		},
+		{
+			"Gamma",
+			func(_ *Tape, x []Var) Var { return GammaLogPDF(x[0], x[1], x[2]) },
+			[]float64{3.2, 2.5, 0.7},
+			func(x []float64) float64 { return distributions.NewGamma(x[1], x[2]).LogPDF(x[0]) },
+		},
+		{
+			"InverseGamma",
+			func(_ *Tape, x []Var) Var { return InverseGammaLogPDF(x[0], x[1], x[2]) },
+			[]float64{0.8, 3, 2},
+			func(x []float64) float64 { return distributions.NewInverseGamma(x[1], x[2]).LogPDF(x[0]) },
+		},
+		{
+			"Exponential",
+			func(_ *Tape, x []Var) Var { return ExponentialLogPDF(x[0], x[1]) },
+			[]float64{1.4, 0.6},
+			func(x []float64) float64 { return distributions.NewGamma(1, x[1]).LogPDF(x[0]) },
+		},
+		{
+			"Binomial",
+			func(_ *Tape, x []Var) Var { return BinomialLogPMF(17, 50, x[0]) },
+			[]float64{0.3},
+			func(x []float64) float64 { return distributions.NewBinomial(50, x[0]).LogPMF(17) },
+		},
+		{
+			"Binomial with no successes",
+			func(_ *Tape, x []Var) Var { return BinomialLogPMF(0, 20, x[0]) },
+			[]float64{0.05},
+			func(x []float64) float64 { return distributions.NewBinomial(20, x[0]).LogPMF(0) },
+		},
+		{
+			"Poisson",
+			func(_ *Tape, x []Var) Var { return PoissonLogPMF(6, x[0]) },
+			[]float64{4.2},
+			func(x []float64) float64 { return distributions.NewPoisson(x[0]).LogPMF(6) },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, _ := Gradient(tt.f, tt.at)
+			if want := tt.want(tt.at); math.Abs(value-want) > 1e-10*math.Max(1, math.Abs(want)) {
+				t.Errorf("value = %.12g, want %.12g", value, want)
+			}
+			checkGradient(t, tt.name, tt.f, tt.at)
+		})
+	}
+}
+
+func TestDensityAdapters(t *testing.T) {
+	f := func(t *Tape, x []Var) Var {
+		return NormalLogPDF(x[0], t.Const(1), t.Const(2)).Add(GammaLogPDF(x[1], t.Const(3), t.Const(1)))
+	}
+	x := []float64{0.4, 2.2}
+	want, wantGrad := Gradient(f, x)
+
+	density := Density(f)
+	grad := make([]float64, 2)
+	for range 2 {
+		// Repeated calls reuse the adapter's tape
+		if got := density.LogDensityGradient(x, grad); got != want {
+			t.Errorf("Density value = %v, want %v", got, want)
+		}
+		for i := range grad {
+			if grad[i] != wantGrad[i] {
+				t.Errorf("Density gradient[%d] = %v, want %v", i, grad[i], wantGrad[i])
+			}
+		}
+	}
+	if got := LogDensity(f)(x); got != want {
+		t.Errorf("LogDensity = %v, want %v", got, want)
+	}
+}
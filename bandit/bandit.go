@@ -0,0 +1,335 @@
+package bandit
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sync"
+
+	"github.com/MyVueCodeHub/myvue-bayes/distributions"
+)
+
+// Strategy selects how a bandit allocates traffic between arms
+type Strategy int
+
+const (
+	// Thompson plays each arm with the posterior probability that it is best
+	Thompson Strategy = iota
+	// TopTwoThompson plays the Thompson choice with probability TopTwoBeta and
+	// otherwise the challenger most likely to beat it, which spreads more
+	// traffic to close contenders and identifies the best arm faster
+	TopTwoThompson
+	// BayesUCB plays the arm with the highest posterior upper quantile, with
+	// the quantile level 1 - 1/(t+1) growing with the number of selections t
+	BayesUCB
+)
+
+// String returns the name of the strategy
+func (s Strategy) String() string {
+	switch s {
+	case TopTwoThompson:
+		return "top_two_thompson"
+	case BayesUCB:
+		return "bayes_ucb"
+	default:
+		return "thompson"
+	}
+}
+
+// maxChallengerDraws bounds the resampling used to find a top-two challenger
+const maxChallengerDraws = 100
+
+// Feedback is the observed reward for a played arm. Context holds the
+// features the arm was chosen with and is ignored by non-contextual bandits.
+type Feedback struct {
+	Arm     string
+	Reward  float64
+	Context []float64
+}
+
+// Learner is the selection and feedback API shared by every bandit in this
+// package, so serving code can switch between plain and contextual bandits
+type Learner interface {
+	// Choose returns the name of the arm to play for the context
+	Choose(context []float64) (string, error)
+
+	// Observe records the reward observed for a played arm
+	Observe(fb Feedback) error
+}
+
+var (
+	_ Learner = (*Bandit)(nil)
+	_ Learner = (*ContextualBandit)(nil)
+)
+
+// Arm holds the prior and accumulated rewards of one arm. Rewards are folded
+// into sufficient statistics when the prior supports it.
+type Arm struct {
+	Name      string
+	Prior     distributions.Prior
+	Data      []float64
+	Stats     distributions.SufficientStatistics
+	Posterior distributions.Posterior
+	Pulls     int
+}
+
+// belief returns the current distribution of the arm's mean reward
+func (a *Arm) belief() distributions.Distribution {
+	if a.Posterior != nil {
+		return a.Posterior
+	}
+	return a.Prior
+}
+
+// observe adds rewards to the arm and refreshes its posterior
+func (a *Arm) observe(rewards ...float64) {
+	a.Pulls += len(rewards)
+	if updater, ok := a.Prior.(distributions.StatisticsUpdater); ok {
+		a.Stats = a.Stats.Add(updater.Statistics(rewards))
+		a.Posterior = updater.UpdateStatistics(a.Stats)
+		return
+	}
+	a.Data = append(a.Data, rewards...)
+	a.Posterior = a.Prior.Update(a.Data)
+}
+
+// Bandit is a Bayesian multi-armed bandit with a conjugate prior per arm.
+// All methods are safe for concurrent use, so a single bandit can serve
+// arm selections and absorb reward feedback from many goroutines.
+type Bandit struct {
+	Strategy Strategy
+	// TopTwoBeta is the probability of playing the leader under TopTwoThompson
+	TopTwoBeta float64
+
+	mu         sync.Mutex
+	arms       []*Arm
+	index      map[string]int
+	selections int
+	src        rand.Source
+	rng        *rand.Rand
+}
+
+// NewBandit creates a bandit with the given strategy and no arms
+func NewBandit(strategy Strategy) *Bandit {
+	return &Bandit{
+		Strategy:   strategy,
+		TopTwoBeta: 0.5,
+		index:      make(map[string]int),
+	}
+}
+
+// NewBernoulliBandit creates a bandit for binary rewards with Beta(1,1) priors
+func NewBernoulliBandit(strategy Strategy, names ...string) *Bandit {
+	b := NewBandit(strategy)
+	for _, name := range names {
+		b.AddArm(name, distributions.NewBeta(1, 1))
+	}
+	return b
+}
+
+// NewGaussianBandit creates a bandit for continuous rewards with known
+// variance, using a Normal(mu, sigma) prior on each arm's mean reward
+func NewGaussianBandit(strategy Strategy, mu, sigma, knownVariance float64, names ...string) *Bandit {
+	b := NewBandit(strategy)
+	for _, name := range names {
+		b.AddArm(name, distributions.NewNormalConjugate(mu, sigma, knownVariance))
+	}
+	return b
+}
+
+// AddArm adds an arm with the given prior. It returns an error if an arm with
+// the same name already exists. The bandit keeps the prior and sets its
+// random source to the bandit's, so a prior should not be shared with other
+// bandits or tests.
+func (b *Bandit) AddArm(name string, prior distributions.Prior) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.index[name]; ok {
+		return fmt.Errorf("arm %q already exists", name)
+	}
+	if b.src != nil {
+		distributions.UseSource(prior, b.src)
+	}
+	b.index[name] = len(b.arms)
+	b.arms = append(b.arms, &Arm{Name: name, Prior: prior})
+	return nil
+}
+
+// SetSource sets the random source used for arm selection. It also sets the
+// source of every arm's prior and posterior.
+func (b *Bandit) SetSource(src rand.Source) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.src = src
+	b.rng = nil
+	if src != nil {
+		b.rng = rand.New(src)
+	}
+	for _, arm := range b.arms {
+		distributions.UseSource(arm.Prior, src)
+		if arm.Posterior != nil {
+			distributions.UseSource(arm.Posterior, src)
+		}
+	}
+}
+
+// SelectArm returns the name of the arm to play next. It returns an empty
+// string if the bandit has no arms.
+func (b *Bandit) SelectArm() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.arms) == 0 {
+		return ""
+	}
+
+	b.selections++
+	var i int
+	switch b.Strategy {
+	case TopTwoThompson:
+		i = b.topTwo()
+	case BayesUCB:
+		i = b.upperQuantile()
+	default:
+		i = b.thompson()
+	}
+	return b.arms[i].Name
+}
+
+// Choose returns the name of the arm to play next; the context is ignored
+func (b *Bandit) Choose(context []float64) (string, error) {
+	arm := b.SelectArm()
+	if arm == "" {
+		return "", fmt.Errorf("bandit has no arms")
+	}
+	return arm, nil
+}
+
+// Update records a reward for the named arm
+func (b *Bandit) Update(arm string, reward float64) error {
+	return b.Observe(Feedback{Arm: arm, Reward: reward})
+}
+
+// Observe records reward feedback. It returns an error for an unknown arm.
+func (b *Bandit) Observe(fb Feedback) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	i, ok := b.index[fb.Arm]
+	if !ok {
+		return fmt.Errorf("unknown arm %q", fb.Arm)
+	}
+	arm := b.arms[i]
+	arm.observe(fb.Reward)
+	if b.src != nil {
+		distributions.UseSource(arm.Posterior, b.src)
+	}
+	return nil
+}
+
+// Arms returns the arm names in the order they were added
+func (b *Bandit) Arms() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	names := make([]string, len(b.arms))
+	for i, arm := range b.arms {
+		names[i] = arm.Name
+	}
+	return names
+}
+
+// Pulls returns the number of rewards observed for each arm
+func (b *Bandit) Pulls() map[string]int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	pulls := make(map[string]int, len(b.arms))
+	for _, arm := range b.arms {
+		pulls[arm.Name] = arm.Pulls
+	}
+	return pulls
+}
+
+// Means returns the posterior mean reward of each arm
+func (b *Bandit) Means() map[string]float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	means := make(map[string]float64, len(b.arms))
+	for _, arm := range b.arms {
+		means[arm.Name] = arm.belief().Mean()
+	}
+	return means
+}
+
+// ProbabilityToBeBest estimates, by Monte Carlo, the posterior probability
+// that each arm has the highest mean reward
+func (b *Bandit) ProbabilityToBeBest() map[string]float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	nSamples := 10000
+	wins := make([]int, len(b.arms))
+	for s := 0; s < nSamples && len(b.arms) > 0; s++ {
+		wins[b.thompson()]++
+	}
+
+	probs := make(map[string]float64, len(b.arms))
+	for i, arm := range b.arms {
+		probs[arm.Name] = float64(wins[i]) / float64(nSamples)
+	}
+	return probs
+}
+
+// thompson draws one sample from each arm and returns the index of the largest
+func (b *Bandit) thompson() int {
+	best, bestValue := 0, math.Inf(-1)
+	for i, arm := range b.arms {
+		if v := arm.belief().Sample(); v > bestValue {
+			best, bestValue = i, v
+		}
+	}
+	return best
+}
+
+// topTwo plays the Thompson leader with probability TopTwoBeta and otherwise
+// resamples until a different arm leads
+func (b *Bandit) topTwo() int {
+	leader := b.thompson()
+	if len(b.arms) == 1 || b.float64() < b.TopTwoBeta {
+		return leader
+	}
+	for draw := 0; draw < maxChallengerDraws; draw++ {
+		if challenger := b.thompson(); challenger != leader {
+			return challenger
+		}
+	}
+	return leader
+}
+
+// upperQuantile returns the arm with the highest posterior quantile at level
+// 1 - 1/(t+1), where t counts the selections so far including this one.
+// Selections rather than observed rewards drive the level, so it keeps rising
+// while feedback is delayed.
+func (b *Bandit) upperQuantile() int {
+	level := 1 - 1/float64(b.selections+1)
+
+	best, bestValue := 0, math.Inf(-1)
+	for i, arm := range b.arms {
+		if v := arm.belief().Quantile(level); v > bestValue {
+			best, bestValue = i, v
+		}
+	}
+	return best
+}
+
+// float64 returns a uniform draw from the bandit's source, or the global source
+func (b *Bandit) float64() float64 {
+	if b.rng != nil {
+		return b.rng.Float64()
+	}
+	return rand.Float64()
+}
@@ -0,0 +1,233 @@
+package bandit
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sync"
+	"testing"
+
+	"github.com/MyVueCodeHub/myvue-bayes/distributions"
+)
+
+var strategies = []Strategy{Thompson, TopTwoThompson, BayesUCB}
+
+// runBernoulli plays the bandit for rounds selections against arms that
+// convert at the given rates
+func runBernoulli(t *testing.T, b *Bandit, rates map[string]float64, rounds int, rng *rand.Rand) {
+	t.Helper()
+	for range rounds {
+		arm := b.SelectArm()
+		reward := 0.0
+		if rng.Float64() < rates[arm] {
+			reward = 1
+		}
+		if err := b.Update(arm, reward); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestBernoulliBanditConvergesToBestArm(t *testing.T) {
+	rates := map[string]float64{"low": 0.1, "mid": 0.2, "high": 0.3}
+	for _, strategy := range strategies {
+		b := NewBernoulliBandit(strategy, "low", "mid", "high")
+		b.SetSource(distributions.NewSource(1))
+		runBernoulli(t, b, rates, 3000, rand.New(rand.NewPCG(1, 1)))
+
+		// Top-two sampling deliberately spends more traffic on the runner-up,
+		// so it concentrates less on the best arm
+		share := 0.7
+		if strategy == TopTwoThompson {
+			share = 0.6
+		}
+		if pulls := b.Pulls()["high"]; float64(pulls) < share*3000 {
+			t.Errorf("%v: best arm pulled %d of 3000 times, want at least %.0f%%", strategy, pulls, 100*share)
+		}
+		if p := b.ProbabilityToBeBest()["high"]; p < 0.95 {
+			t.Errorf("%v: P(best arm is best) = %v, want at least 0.95", strategy, p)
+		}
+		if mean := b.Means()["high"]; math.Abs(mean-0.3) > 0.03 {
+			t.Errorf("%v: best arm mean = %v, want about 0.3", strategy, mean)
+		}
+	}
+}
+
+func TestGaussianBanditConvergesToBestArm(t *testing.T) {
+	means := map[string]float64{"a": 1, "b": 1.5, "c": 0.5}
+	for _, strategy := range strategies {
+		b := NewGaussianBandit(strategy, 0, 10, 1, "a", "b", "c")
+		b.SetSource(distributions.NewSource(2))
+		rng := rand.New(rand.NewPCG(2, 2))
+		for range 2000 {
+			arm := b.SelectArm()
+			b.Update(arm, means[arm]+rng.NormFloat64())
+		}
+
+		share := 0.7
+		if strategy == TopTwoThompson {
+			share = 0.6
+		}
+		if pulls := b.Pulls()["b"]; float64(pulls) < share*2000 {
+			t.Errorf("%v: best arm pulled %d of 2000 times, want at least %.0f%%", strategy, pulls, 100*share)
+		}
+	}
+}
+
+func TestBanditIsDeterministicWithSource(t *testing.T) {
+	rates := map[string]float64{"a": 0.3, "b": 0.35, "c": 0.4}
+	for _, strategy := range strategies {
+		run := func() []string {
+			b := NewBernoulliBandit(strategy, "a", "b", "c")
+			b.SetSource(distributions.NewSource(3))
+			rng := rand.New(rand.NewPCG(3, 3))
+			var choices []string
+			for range 200 {
+				arm := b.SelectArm()
+				choices = append(choices, arm)
+				reward := 0.0
+				if rng.Float64() < rates[arm] {
+					reward = 1
+				}
+				b.Update(arm, reward)
+			}
+			return choices
+		}
+		first, second := run(), run()
+		for i := range first {
+			if first[i] != second[i] {
+				t.Errorf("%v: choice %d was %s then %s with the same source", strategy, i, first[i], second[i])
+				break
+			}
+		}
+	}
+}
+
+func TestBanditSourceSetBeforeArms(t *testing.T) {
+	run := func() []string {
+		b := NewBandit(Thompson)
+		b.SetSource(distributions.NewSource(4))
+		b.AddArm("a", distributions.NewBeta(1, 1))
+		b.AddArm("b", distributions.NewBeta(1, 1))
+		var choices []string
+		for range 50 {
+			choices = append(choices, b.SelectArm())
+		}
+		return choices
+	}
+	first, second := run(), run()
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("choice %d was %s then %s with the same source", i, first[i], second[i])
+		}
+	}
+}
+
+func TestBanditErrors(t *testing.T) {
+	b := NewBandit(Thompson)
+	if arm := b.SelectArm(); arm != "" {
+		t.Errorf("SelectArm with no arms = %q, want empty", arm)
+	}
+	if _, err := b.Choose(nil); err == nil {
+		t.Error("Choose with no arms: want error")
+	}
+
+	if err := b.AddArm("a", distributions.NewBeta(1, 1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.AddArm("a", distributions.NewBeta(2, 2)); err == nil {
+		t.Error("AddArm with a duplicate name: want error")
+	}
+	if got := b.Arms(); len(got) != 1 {
+		t.Errorf("arms after a rejected duplicate = %v, want [a]", got)
+	}
+	if err := b.Update("missing", 1); err == nil {
+		t.Error("Update of an unknown arm: want error")
+	}
+	if err := b.Observe(Feedback{Arm: "missing", Reward: 1}); err == nil {
+		t.Error("Observe of an unknown arm: want error")
+	}
+	if pulls := b.Pulls()["a"]; pulls != 0 {
+		t.Errorf("pulls after rejected feedback = %d, want 0", pulls)
+	}
+}
+
+func TestBayesUCBExploresUntriedArms(t *testing.T) {
+	b := NewBernoulliBandit(BayesUCB, "a", "b", "c")
+	seen := make(map[string]bool)
+	for range 3 {
+		arm := b.SelectArm()
+		seen[arm] = true
+		b.Update(arm, 0)
+	}
+	if len(seen) != 3 {
+		t.Errorf("first three selections played %v, want every arm once", seen)
+	}
+}
+
+func TestBanditConcurrentUse(t *testing.T) {
+	// Run with -race to check that selection and feedback are synchronized
+	for _, strategy := range strategies {
+		b := NewBernoulliBandit(strategy, "a", "b", "c")
+		b.SetSource(distributions.NewSource(5))
+
+		const workers, rounds = 8, 200
+		var wg sync.WaitGroup
+		for w := range workers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range rounds {
+					arm, err := b.Choose(nil)
+					if err != nil {
+						t.Error(err)
+						return
+					}
+					if err := b.Observe(Feedback{Arm: arm, Reward: float64((w + i) % 2)}); err != nil {
+						t.Error(err)
+						return
+					}
+					if i%50 == 0 {
+						b.Means()
+						b.ProbabilityToBeBest()
+					}
+				}
+			}()
+		}
+		wg.Wait()
+
+		total := 0
+		for _, pulls := range b.Pulls() {
+			total += pulls
+		}
+		if total != workers*rounds {
+			t.Errorf("%v: %d pulls recorded, want %d", strategy, total, workers*rounds)
+		}
+	}
+}
+
+func TestContextualBanditConcurrentUse(t *testing.T) {
+	cb := NewLogisticBandit(2, 1, "a", "b")
+	cb.SetSource(distributions.NewSource(6))
+
+	var wg sync.WaitGroup
+	for w := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 100 {
+				context := []float64{1, float64(w%3) - 1}
+				arm, err := cb.Choose(context)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if err := cb.Observe(Feedback{Arm: arm, Reward: float64(i % 2), Context: context}); err != nil {
+					t.Error(fmt.Errorf("worker %d: %v", w, err))
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
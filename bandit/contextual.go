@@ -0,0 +1,519 @@
+package bandit
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sync"
+
+	"github.com/MyVueCodeHub/myvue-bayes/distributions"
+	"gonum.org/v1/gonum/mat"
+)
+
+// maxNewtonSteps bounds the Newton iterations of each logistic update
+const maxNewtonSteps = 20
+
+// ContextualArm is the reward model of one arm of a contextual bandit. To fit
+// an intercept, include a constant 1 feature in every context.
+type ContextualArm interface {
+	// Dim returns the number of context features
+	Dim() int
+
+	// SampleReward draws parameters from the posterior and returns the
+	// expected reward they imply for the context. It returns an error if the
+	// posterior cannot be computed.
+	SampleReward(context []float64) (float64, error)
+
+	// ExpectedReward returns the posterior expected reward for the context.
+	// It returns an error if the posterior cannot be computed.
+	ExpectedReward(context []float64) (float64, error)
+
+	// Observe updates the posterior with a reward observed for the context
+	Observe(context []float64, reward float64)
+
+	// SetSource sets the random source used for sampling
+	SetSource(src rand.Source)
+}
+
+// LinearArm is a Bayesian linear regression of reward on context with a
+// Normal-Inverse-Gamma prior: the noise variance σ² is InverseGamma(Alpha,
+// Beta) and, given σ², the weights are Normal(0, σ²/Lambda I). The posterior
+// depends on the data only through XᵀX, Xᵀy, yᵀy and the count.
+type LinearArm struct {
+	Lambda float64
+	Alpha  float64
+	Beta   float64
+
+	dim int
+	xtx *mat.SymDense
+	xty *mat.VecDense
+	yty float64
+	n   int
+
+	stale  bool
+	chol   mat.Cholesky
+	mean   *mat.VecDense
+	alphaN float64
+	betaN  float64
+
+	rng *rand.Rand
+}
+
+// NewLinearArm creates a linear arm for contexts with dim features. It panics
+// if dim is not positive or lambda, alpha or beta is not positive.
+func NewLinearArm(dim int, lambda, alpha, beta float64) *LinearArm {
+	if dim < 1 {
+		panic(fmt.Sprintf("bandit: linear arm dimension %d is not positive", dim))
+	}
+	if !(lambda > 0 && alpha > 0 && beta > 0) {
+		panic(fmt.Sprintf("bandit: linear arm prior (lambda %v, alpha %v, beta %v) must be positive", lambda, alpha, beta))
+	}
+	return &LinearArm{
+		Lambda: lambda,
+		Alpha:  alpha,
+		Beta:   beta,
+		dim:    dim,
+		xtx:    mat.NewSymDense(dim, nil),
+		xty:    mat.NewVecDense(dim, nil),
+		stale:  true,
+	}
+}
+
+// Dim returns the number of context features
+func (la *LinearArm) Dim() int {
+	return la.dim
+}
+
+// SetSource sets the random source used for sampling
+func (la *LinearArm) SetSource(src rand.Source) {
+	la.rng = newRand(src)
+}
+
+// Observe updates the posterior with a reward observed for the context
+func (la *LinearArm) Observe(context []float64, reward float64) {
+	x := mat.NewVecDense(la.dim, context)
+	la.xtx.SymRankOne(la.xtx, 1, x)
+	la.xty.AddScaledVec(la.xty, reward, x)
+	la.yty += reward * reward
+	la.n++
+	la.stale = true
+}
+
+// Weights returns the posterior mean of the regression weights, or nil if
+// the posterior cannot be computed
+func (la *LinearArm) Weights() []float64 {
+	if la.refresh() != nil {
+		return nil
+	}
+	return mat.Col(nil, 0, la.mean)
+}
+
+// NoiseVariance returns the posterior distribution of the noise variance, or
+// nil if the posterior cannot be computed
+func (la *LinearArm) NoiseVariance() *distributions.InverseGamma {
+	if la.refresh() != nil {
+		return nil
+	}
+	return distributions.NewInverseGamma(la.alphaN, la.betaN)
+}
+
+// ExpectedReward returns the posterior expected reward for the context
+func (la *LinearArm) ExpectedReward(context []float64) (float64, error) {
+	if err := la.refresh(); err != nil {
+		return 0, err
+	}
+	return mat.Dot(la.mean, mat.NewVecDense(la.dim, context)), nil
+}
+
+// SampleReward draws σ² and the weights from the posterior and returns the
+// expected reward they imply for the context
+func (la *LinearArm) SampleReward(context []float64) (float64, error) {
+	if err := la.refresh(); err != nil {
+		return 0, err
+	}
+
+	noise := distributions.NewInverseGamma(la.alphaN, la.betaN)
+	if la.rng != nil {
+		noise.SetSource(la.rng)
+	}
+	w := sampleGaussian(la.mean, &la.chol, math.Sqrt(noise.Sample()), la.rng)
+	return mat.Dot(w, mat.NewVecDense(la.dim, context)), nil
+}
+
+// refresh recomputes the posterior after new observations. It returns an
+// error if the posterior precision XᵀX + Lambda I is not positive definite,
+// which happens when Lambda is not positive or the data are not finite.
+func (la *LinearArm) refresh() error {
+	if !la.stale {
+		return nil
+	}
+
+	precision := mat.NewSymDense(la.dim, nil)
+	precision.CopySym(la.xtx)
+	for i := 0; i < la.dim; i++ {
+		precision.SetSym(i, i, precision.At(i, i)+la.Lambda)
+	}
+	if !la.chol.Factorize(precision) {
+		return fmt.Errorf("linear arm posterior precision is not positive definite")
+	}
+
+	la.mean = mat.NewVecDense(la.dim, nil)
+	if err := la.chol.SolveVecTo(la.mean, la.xty); err != nil {
+		return fmt.Errorf("linear arm posterior mean: %v", err)
+	}
+
+	la.alphaN = la.Alpha + float64(la.n)/2
+	la.betaN = math.Max(la.Beta+(la.yty-mat.Dot(la.mean, la.xty))/2, la.Beta*1e-12)
+	la.stale = false
+	return nil
+}
+
+// LogisticArm is a Bayesian logistic regression of a binary reward on
+// context. The weights have a Normal(0, PriorVariance I) prior and the
+// posterior is kept as a Gaussian by an online Laplace approximation: each
+// observation moves the mean to the new posterior mode and adds the curvature
+// of its log-likelihood to the precision.
+type LogisticArm struct {
+	PriorVariance float64
+
+	dim       int
+	mean      *mat.VecDense
+	precision *mat.SymDense
+
+	stale bool
+	chol  mat.Cholesky
+
+	rng *rand.Rand
+}
+
+// NewLogisticArm creates a logistic arm for contexts with dim features. It
+// panics if dim or priorVariance is not positive.
+func NewLogisticArm(dim int, priorVariance float64) *LogisticArm {
+	if dim < 1 {
+		panic(fmt.Sprintf("bandit: logistic arm dimension %d is not positive", dim))
+	}
+	if !(priorVariance > 0) {
+		panic(fmt.Sprintf("bandit: logistic arm prior variance %v is not positive", priorVariance))
+	}
+	precision := mat.NewSymDense(dim, nil)
+	for i := 0; i < dim; i++ {
+		precision.SetSym(i, i, 1/priorVariance)
+	}
+	return &LogisticArm{
+		PriorVariance: priorVariance,
+		dim:           dim,
+		mean:          mat.NewVecDense(dim, nil),
+		precision:     precision,
+		stale:         true,
+	}
+}
+
+// Dim returns the number of context features
+func (lr *LogisticArm) Dim() int {
+	return lr.dim
+}
+
+// SetSource sets the random source used for sampling
+func (lr *LogisticArm) SetSource(src rand.Source) {
+	lr.rng = newRand(src)
+}
+
+// Weights returns the posterior mean of the regression weights
+func (lr *LogisticArm) Weights() []float64 {
+	return mat.Col(nil, 0, lr.mean)
+}
+
+// Observe updates the posterior with a binary reward observed for the context
+func (lr *LogisticArm) Observe(context []float64, reward float64) {
+	x := mat.NewVecDense(lr.dim, context)
+	y := 0.0
+	if reward > 0 {
+		y = 1
+	}
+
+	// Newton's method on the negative log posterior
+	// ½(w-m)ᵀP(w-m) - log p(y | w, x)
+	w := mat.VecDenseCopyOf(lr.mean)
+	var p float64
+	var chol mat.Cholesky
+	for step := 0; step < maxNewtonSteps; step++ {
+		p = sigmoid(mat.Dot(w, x))
+
+		diff := mat.NewVecDense(lr.dim, nil)
+		diff.SubVec(w, lr.mean)
+		grad := mat.NewVecDense(lr.dim, nil)
+		grad.MulVec(lr.precision, diff)
+		grad.AddScaledVec(grad, -(y - p), x)
+
+		hessian := mat.NewSymDense(lr.dim, nil)
+		hessian.SymRankOne(lr.precision, p*(1-p), x)
+		if !chol.Factorize(hessian) {
+			break
+		}
+		delta := mat.NewVecDense(lr.dim, nil)
+		if err := chol.SolveVecTo(delta, grad); err != nil {
+			break
+		}
+		w.SubVec(w, delta)
+
+		if mat.Norm(delta, math.Inf(1)) < 1e-10 {
+			break
+		}
+	}
+
+	p = sigmoid(mat.Dot(w, x))
+	lr.mean = w
+	lr.precision.SymRankOne(lr.precision, p*(1-p), x)
+	lr.stale = true
+}
+
+// ExpectedReward returns the posterior probability of a reward for the
+// context, using the probit approximation to integrate over the weights
+func (lr *LogisticArm) ExpectedReward(context []float64) (float64, error) {
+	if err := lr.refresh(); err != nil {
+		return 0, err
+	}
+
+	x := mat.NewVecDense(lr.dim, context)
+	v := mat.NewVecDense(lr.dim, nil)
+	if err := lr.chol.SolveVecTo(v, x); err != nil {
+		return 0, fmt.Errorf("logistic arm posterior variance: %v", err)
+	}
+	variance := mat.Dot(x, v)
+	return sigmoid(mat.Dot(lr.mean, x) / math.Sqrt(1+math.Pi*variance/8)), nil
+}
+
+// SampleReward draws the weights from the posterior and returns the reward
+// probability they imply for the context
+func (lr *LogisticArm) SampleReward(context []float64) (float64, error) {
+	if err := lr.refresh(); err != nil {
+		return 0, err
+	}
+
+	w := sampleGaussian(lr.mean, &lr.chol, 1, lr.rng)
+	return sigmoid(mat.Dot(w, mat.NewVecDense(lr.dim, context))), nil
+}
+
+// refresh factorizes the precision after new observations. It returns an
+// error if the precision is not positive definite.
+func (lr *LogisticArm) refresh() error {
+	if !lr.stale {
+		return nil
+	}
+	if !lr.chol.Factorize(lr.precision) {
+		return fmt.Errorf("logistic arm posterior precision is not positive definite")
+	}
+	lr.stale = false
+	return nil
+}
+
+// sampleGaussian draws from Normal(mean, scale² P⁻¹) given the Cholesky
+// factorization P = UᵀU of the precision
+func sampleGaussian(mean *mat.VecDense, chol *mat.Cholesky, scale float64, rng *rand.Rand) *mat.VecDense {
+	n := mean.Len()
+	z := mat.NewVecDense(n, nil)
+	for i := 0; i < n; i++ {
+		if rng != nil {
+			z.SetVec(i, rng.NormFloat64())
+		} else {
+			z.SetVec(i, rand.NormFloat64())
+		}
+	}
+
+	var u mat.TriDense
+	chol.UTo(&u)
+	w := mat.NewVecDense(n, nil)
+	w.SolveVec(&u, z)
+	w.AddScaledVec(mean, scale, w)
+	return w
+}
+
+// sigmoid returns the logistic function of x
+func sigmoid(x float64) float64 {
+	if x >= 0 {
+		return 1 / (1 + math.Exp(-x))
+	}
+	e := math.Exp(x)
+	return e / (1 + e)
+}
+
+// newRand returns a generator for src, or nil to use the global source
+func newRand(src rand.Source) *rand.Rand {
+	if src == nil {
+		return nil
+	}
+	return rand.New(src)
+}
+
+// ContextualBandit chooses between arms whose reward depends on a context
+// vector, using Thompson sampling over each arm's regression posterior.
+// All methods are safe for concurrent use.
+type ContextualBandit struct {
+	mu    sync.Mutex
+	arms  []contextualEntry
+	index map[string]int
+	src   rand.Source
+}
+
+// contextualEntry is a named arm of a contextual bandit
+type contextualEntry struct {
+	name string
+	arm  ContextualArm
+}
+
+// NewContextualBandit creates a contextual bandit with no arms
+func NewContextualBandit() *ContextualBandit {
+	return &ContextualBandit{index: make(map[string]int)}
+}
+
+// NewLinearBandit creates a contextual bandit of linear arms with a
+// Normal-Inverse-Gamma(0, lambda, alpha, beta) prior on each arm. It panics
+// if dim or any prior parameter is not positive.
+func NewLinearBandit(dim int, lambda, alpha, beta float64, names ...string) *ContextualBandit {
+	cb := NewContextualBandit()
+	for _, name := range names {
+		cb.AddArm(name, NewLinearArm(dim, lambda, alpha, beta))
+	}
+	return cb
+}
+
+// NewLogisticBandit creates a contextual bandit of logistic arms with a
+// Normal(0, priorVariance I) prior on each arm's weights. It panics if dim or
+// priorVariance is not positive.
+func NewLogisticBandit(dim int, priorVariance float64, names ...string) *ContextualBandit {
+	cb := NewContextualBandit()
+	for _, name := range names {
+		cb.AddArm(name, NewLogisticArm(dim, priorVariance))
+	}
+	return cb
+}
+
+// AddArm adds a named arm. It returns an error if the name is taken or the
+// arm's dimension differs from the existing arms.
+func (cb *ContextualBandit) AddArm(name string, arm ContextualArm) error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if _, ok := cb.index[name]; ok {
+		return fmt.Errorf("arm %q already exists", name)
+	}
+	if len(cb.arms) > 0 && arm.Dim() != cb.arms[0].arm.Dim() {
+		return fmt.Errorf("arm %q has dimension %d, want %d", name, arm.Dim(), cb.arms[0].arm.Dim())
+	}
+	if cb.src != nil {
+		arm.SetSource(cb.src)
+	}
+	cb.index[name] = len(cb.arms)
+	cb.arms = append(cb.arms, contextualEntry{name: name, arm: arm})
+	return nil
+}
+
+// SetSource sets the random source used for arm selection
+func (cb *ContextualBandit) SetSource(src rand.Source) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.src = src
+	for _, entry := range cb.arms {
+		entry.arm.SetSource(src)
+	}
+}
+
+// SelectArm returns the name of the arm to play for the context
+func (cb *ContextualBandit) SelectArm(context []float64) (string, error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if err := cb.checkContext(context); err != nil {
+		return "", err
+	}
+
+	best, bestValue := 0, math.Inf(-1)
+	for i, entry := range cb.arms {
+		v, err := entry.arm.SampleReward(context)
+		if err != nil {
+			return "", fmt.Errorf("arm %q: %v", entry.name, err)
+		}
+		if v > bestValue {
+			best, bestValue = i, v
+		}
+	}
+	return cb.arms[best].name, nil
+}
+
+// Choose returns the name of the arm to play for the context
+func (cb *ContextualBandit) Choose(context []float64) (string, error) {
+	return cb.SelectArm(context)
+}
+
+// Update records a reward observed for the named arm and context
+func (cb *ContextualBandit) Update(arm string, context []float64, reward float64) error {
+	return cb.Observe(Feedback{Arm: arm, Reward: reward, Context: context})
+}
+
+// Observe records reward feedback. It returns an error for an unknown arm, a
+// context of the wrong dimension or a non-finite context or reward.
+func (cb *ContextualBandit) Observe(fb Feedback) error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	i, ok := cb.index[fb.Arm]
+	if !ok {
+		return fmt.Errorf("unknown arm %q", fb.Arm)
+	}
+	if err := cb.checkContext(fb.Context); err != nil {
+		return err
+	}
+	if math.IsNaN(fb.Reward) || math.IsInf(fb.Reward, 0) {
+		return fmt.Errorf("reward is %v", fb.Reward)
+	}
+	cb.arms[i].arm.Observe(fb.Context, fb.Reward)
+	return nil
+}
+
+// Arms returns the arm names in the order they were added
+func (cb *ContextualBandit) Arms() []string {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	names := make([]string, len(cb.arms))
+	for i, entry := range cb.arms {
+		names[i] = entry.name
+	}
+	return names
+}
+
+// ExpectedRewards returns the posterior expected reward of each arm for the context
+func (cb *ContextualBandit) ExpectedRewards(context []float64) (map[string]float64, error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if err := cb.checkContext(context); err != nil {
+		return nil, err
+	}
+	rewards := make(map[string]float64, len(cb.arms))
+	for _, entry := range cb.arms {
+		reward, err := entry.arm.ExpectedReward(context)
+		if err != nil {
+			return nil, fmt.Errorf("arm %q: %v", entry.name, err)
+		}
+		rewards[entry.name] = reward
+	}
+	return rewards, nil
+}
+
+// checkContext validates a context against the arms' dimension
+func (cb *ContextualBandit) checkContext(context []float64) error {
+	if len(cb.arms) == 0 {
+		return fmt.Errorf("bandit has no arms")
+	}
+	if dim := cb.arms[0].arm.Dim(); len(context) != dim {
+		return fmt.Errorf("context has %d features, want %d", len(context), dim)
+	}
+	for i, x := range context {
+		if math.IsNaN(x) || math.IsInf(x, 0) {
+			return fmt.Errorf("context feature %d is %v", i, x)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+package bandit
+
+import (
+	"math"
+	"math/rand/v2"
+	"strings"
+	"testing"
+
+	"github.com/MyVueCodeHub/myvue-bayes/distributions"
+)
+
+func TestLinearArmRecoversWeights(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 1))
+	weights := []float64{1, -2, 0.5}
+	const noiseSD = 0.1
+
+	// A weak prior, so the noise variance is not inflated by the prior's
+	// Beta or its penalty on the weights
+	arm := NewLinearArm(3, 0.01, 0.01, 0.01)
+	for range 2000 {
+		x := []float64{1, rng.NormFloat64(), rng.NormFloat64()}
+		y := weights[0]*x[0] + weights[1]*x[1] + weights[2]*x[2] + noiseSD*rng.NormFloat64()
+		arm.Observe(x, y)
+	}
+
+	for i, w := range arm.Weights() {
+		if math.Abs(w-weights[i]) > 0.02 {
+			t.Errorf("weight %d = %v, want %v", i, w, weights[i])
+		}
+	}
+	if got := arm.NoiseVariance().Mean(); math.Abs(got-noiseSD*noiseSD) > 0.001 {
+		t.Errorf("noise variance = %v, want %v", got, noiseSD*noiseSD)
+	}
+
+	context := []float64{1, 0.5, -1}
+	want := weights[0] + 0.5*weights[1] - weights[2]
+	if got, err := arm.ExpectedReward(context); err != nil || math.Abs(got-want) > 0.05 {
+		t.Errorf("ExpectedReward = %v, %v, want %v", got, err, want)
+	}
+}
+
+func TestLogisticArmRecoversWeights(t *testing.T) {
+	rng := rand.New(rand.NewPCG(2, 2))
+	weights := []float64{-0.5, 1.5}
+
+	arm := NewLogisticArm(2, 10)
+	for range 5000 {
+		x := []float64{1, rng.NormFloat64()}
+		reward := 0.0
+		if rng.Float64() < sigmoid(weights[0]*x[0]+weights[1]*x[1]) {
+			reward = 1
+		}
+		arm.Observe(x, reward)
+	}
+
+	for i, w := range arm.Weights() {
+		if math.Abs(w-weights[i]) > 0.15 {
+			t.Errorf("weight %d = %v, want %v", i, w, weights[i])
+		}
+	}
+	context := []float64{1, 1}
+	if got, err := arm.ExpectedReward(context); err != nil || math.Abs(got-sigmoid(1)) > 0.03 {
+		t.Errorf("ExpectedReward = %v, %v, want %v", got, err, sigmoid(1))
+	}
+}
+
+func TestContextualBanditLearnsContextDependentBestArm(t *testing.T) {
+	rng := rand.New(rand.NewPCG(3, 3))
+	cb := NewLinearBandit(2, 1, 1, 1, "a", "b")
+	cb.SetSource(distributions.NewSource(3))
+
+	// Arm a pays more when the feature is negative, arm b when it is positive
+	reward := map[string]func(x float64) float64{
+		"a": func(x float64) float64 { return -x },
+		"b": func(x float64) float64 { return x },
+	}
+	for range 1000 {
+		context := []float64{1, 2*rng.Float64() - 1}
+		arm, err := cb.Choose(context)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := reward[arm](context[1]) + 0.1*rng.NormFloat64()
+		if err := cb.Observe(Feedback{Arm: arm, Reward: r, Context: context}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tt := range []struct {
+		x    float64
+		want string
+	}{{-0.8, "a"}, {0.8, "b"}} {
+		wins := 0
+		for range 100 {
+			if arm, _ := cb.Choose([]float64{1, tt.x}); arm == tt.want {
+				wins++
+			}
+		}
+		if wins < 95 {
+			t.Errorf("at x = %v: chose %s %d of 100 times", tt.x, tt.want, wins)
+		}
+	}
+}
+
+func TestContextualBanditIsDeterministicWithSource(t *testing.T) {
+	choices := func() []string {
+		cb := NewLogisticBandit(2, 1, "a", "b", "c")
+		cb.SetSource(distributions.NewSource(7))
+		var out []string
+		for i := range 50 {
+			context := []float64{1, float64(i%5) - 2}
+			arm, err := cb.Choose(context)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out = append(out, arm)
+			cb.Update(arm, context, float64(i%2))
+		}
+		return out
+	}
+	first, second := choices(), choices()
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("choice %d: %s then %s with the same source", i, first[i], second[i])
+		}
+	}
+}
+
+func TestContextualBanditErrors(t *testing.T) {
+	cb := NewLinearBandit(2, 1, 1, 1, "a")
+
+	if _, err := NewContextualBandit().Choose([]float64{1}); err == nil {
+		t.Error("Choose on a bandit with no arms: want error")
+	}
+	if err := cb.AddArm("a", NewLinearArm(2, 1, 1, 1)); err == nil {
+		t.Error("AddArm with a duplicate name: want error")
+	}
+	if err := cb.AddArm("b", NewLinearArm(3, 1, 1, 1)); err == nil {
+		t.Error("AddArm with a different dimension: want error")
+	}
+	if _, err := cb.Choose([]float64{1, 2, 3}); err == nil {
+		t.Error("Choose with a context of the wrong dimension: want error")
+	}
+	if _, err := cb.ExpectedRewards([]float64{1}); err == nil {
+		t.Error("ExpectedRewards with a context of the wrong dimension: want error")
+	}
+	if err := cb.Update("a", []float64{1}, 1); err == nil {
+		t.Error("Update with a context of the wrong dimension: want error")
+	}
+	if err := cb.Update("z", []float64{1, 2}, 1); err == nil {
+		t.Error("Update of an unknown arm: want error")
+	}
+	if err := cb.Update("a", []float64{1, math.NaN()}, 1); err == nil {
+		t.Error("Update with a NaN feature: want error")
+	}
+	if err := cb.Update("a", []float64{1, 2}, math.Inf(1)); err == nil {
+		t.Error("Update with an infinite reward: want error")
+	}
+}
+
+func TestSingularLinearPosteriorReturnsError(t *testing.T) {
+	arm := NewLinearArm(2, 1, 1, 1)
+	arm.Lambda = 0
+	cb := NewContextualBandit()
+	cb.AddArm("a", arm)
+
+	// One observation leaves XᵀX singular, and without the ridge the
+	// posterior precision is too
+	context := []float64{1, 0}
+	if err := cb.Update("a", context, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cb.Choose(context); err == nil || !strings.Contains(err.Error(), "positive definite") {
+		t.Errorf("Choose with a singular posterior: err = %v", err)
+	}
+	if _, err := cb.ExpectedRewards(context); err == nil {
+		t.Error("ExpectedRewards with a singular posterior: want error")
+	}
+	if w := arm.Weights(); w != nil {
+		t.Errorf("Weights with a singular posterior = %v, want nil", w)
+	}
+}
+
+func TestContextualArmConstructorsRejectInvalidPriors(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		f    func()
+	}{
+		{"zero lambda", func() { NewLinearBandit(2, 0, 1, 1, "a", "b") }},
+		{"negative alpha", func() { NewLinearArm(2, 1, -1, 1) }},
+		{"zero beta", func() { NewLinearArm(2, 1, 1, 0) }},
+		{"zero dimension", func() { NewLinearArm(0, 1, 1, 1) }},
+		{"zero prior variance", func() { NewLogisticBandit(2, 0, "a") }},
+		{"NaN prior variance", func() { NewLogisticArm(2, math.NaN()) }},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: want panic", tt.name)
+				}
+			}()
+			tt.f()
+		}()
+	}
+}
@@ -1,6 +1,8 @@
 package distributions
 
 import (
+	"math/rand/v2"
+
 	"gonum.org/v1/gonum/stat/distuv"
 )
 
@@ -45,6 +47,11 @@ func (b *Beta) Sample() float64 {
 	return b.dist.Rand()
 }
 
+// SetSource sets the random source used for sampling; nil uses the global source
+func (b *Beta) SetSource(src rand.Source) {
+	b.dist.Src = src
+}
+
 // SampleN generates n random samples
 func (b *Beta) SampleN(n int) []float64 {
 	samples := make([]float64, n)
@@ -94,29 +101,38 @@ func (b *Beta) Entropy() float64 {
 
 // Update performs Bayesian update with binomial likelihood
 func (b *Beta) Update(data []float64) Posterior {
-	successes := 0.0
-	trials := float64(len(data))
+	return b.UpdateStatistics(b.Statistics(data))
+}
+
+// Statistics counts trials and successes, treating positive values as successes
+func (b *Beta) Statistics(data []float64) SufficientStatistics {
+	successes := 0
 	for _, x := range data {
 		if x > 0 {
 			successes++
 		}
 	}
+	return BinomialStatistics(successes, len(data))
+}
 
-	return &BetaPosterior{
-		Beta: NewBeta(b.Alpha+successes, b.Beta+trials-successes),
-	}
+// UpdateStatistics performs Bayesian update from trials (N) and successes (Sum)
+func (b *Beta) UpdateStatistics(stats SufficientStatistics) Posterior {
+	return b.posterior(b.Alpha+stats.Sum, b.Beta+stats.N-stats.Sum)
 }
 
 // UpdateSingle updates with a single observation
 func (b *Beta) UpdateSingle(observation float64) Posterior {
 	if observation > 0 {
-		return &BetaPosterior{
-			Beta: NewBeta(b.Alpha+1, b.Beta),
-		}
-	}
-	return &BetaPosterior{
-		Beta: NewBeta(b.Alpha, b.Beta+1),
+		return b.posterior(b.Alpha+1, b.Beta)
 	}
+	return b.posterior(b.Alpha, b.Beta+1)
+}
+
+// posterior returns a BetaPosterior that shares the receiver's random source
+func (b *Beta) posterior(alpha, beta float64) *BetaPosterior {
+	post := NewBeta(alpha, beta)
+	post.SetSource(b.dist.Src)
+	return &BetaPosterior{Beta: post}
 }
 
 // BetaPosterior represents a Beta posterior distribution
@@ -141,7 +157,13 @@ func (bp *BetaPosterior) MAP() float64 {
 
 // HPD returns the highest posterior density interval
 func (bp *BetaPosterior) HPD(confidence float64) (lower, upper float64) {
-	// Simplified implementation - for Beta, often similar to credible interval
-	// In production, use numerical optimization for true HPD
-	return bp.CredibleInterval(confidence)
+	return HPDInterval(bp, confidence)
+}
+
+// PosteriorPredictive returns the Beta-Binomial distribution of the number of
+// successes in the next n trials
+func (bp *BetaPosterior) PosteriorPredictive(n int) Distribution {
+	predictive := NewBetaBinomial(n, bp.Alpha, bp.Beta.Beta)
+	predictive.SetSource(bp.dist.Src)
+	return predictive
 }
@@ -0,0 +1,128 @@
+package distributions
+
+import (
+	"math"
+	"math/rand/v2"
+
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// BetaBinomial represents the number of successes in N trials whose success
+// probability is itself Beta(Alpha, Beta) distributed
+type BetaBinomial struct {
+	N     int
+	Alpha float64
+	Beta  float64
+	src   rand.Source
+}
+
+// NewBetaBinomial creates a new BetaBinomial distribution
+func NewBetaBinomial(n int, alpha, beta float64) *BetaBinomial {
+	return &BetaBinomial{
+		N:     n,
+		Alpha: alpha,
+		Beta:  beta,
+	}
+}
+
+// PMF returns the probability mass function at k
+func (bb *BetaBinomial) PMF(k int) float64 {
+	return math.Exp(bb.LogPMF(k))
+}
+
+// LogPMF returns the log probability mass function at k
+func (bb *BetaBinomial) LogPMF(k int) float64 {
+	if k < 0 || k > bb.N {
+		return math.Inf(-1)
+	}
+	n, kf := float64(bb.N), float64(k)
+	return logChoose(n, kf) + logBeta(kf+bb.Alpha, n-kf+bb.Beta) - logBeta(bb.Alpha, bb.Beta)
+}
+
+// PDF returns the probability mass at x, which is zero for non-integer x
+func (bb *BetaBinomial) PDF(x float64) float64 {
+	if x != math.Floor(x) {
+		return 0
+	}
+	return bb.PMF(int(x))
+}
+
+// LogPDF returns the log probability mass at x
+func (bb *BetaBinomial) LogPDF(x float64) float64 {
+	if x != math.Floor(x) {
+		return math.Inf(-1)
+	}
+	return bb.LogPMF(int(x))
+}
+
+// CDF returns the cumulative distribution function at x
+func (bb *BetaBinomial) CDF(x float64) float64 {
+	if x < 0 {
+		return 0
+	}
+	if x >= float64(bb.N) {
+		return 1
+	}
+	cdf := 0.0
+	for k := 0; k <= int(x); k++ {
+		cdf += bb.PMF(k)
+	}
+	return math.Min(cdf, 1)
+}
+
+// Quantile returns the smallest integer k with CDF(k) >= p
+func (bb *BetaBinomial) Quantile(p float64) float64 {
+	cdf := func(k int) float64 { return bb.CDF(float64(k)) }
+	return discreteQuantile(cdf, p, int(math.Ceil(bb.Mean())), bb.N)
+}
+
+// Sample generates a random sample by drawing a success probability and then
+// a Binomial count
+func (bb *BetaBinomial) Sample() float64 {
+	p := distuv.Beta{Alpha: bb.Alpha, Beta: bb.Beta, Src: bb.src}.Rand()
+	return distuv.Binomial{N: float64(bb.N), P: p, Src: bb.src}.Rand()
+}
+
+// SetSource sets the random source used for sampling; nil uses the global source
+func (bb *BetaBinomial) SetSource(src rand.Source) {
+	bb.src = src
+}
+
+// SampleN generates n random samples
+func (bb *BetaBinomial) SampleN(n int) []float64 {
+	samples := make([]float64, n)
+	for i := 0; i < n; i++ {
+		samples[i] = bb.Sample()
+	}
+	return samples
+}
+
+// Mean returns the expected value
+func (bb *BetaBinomial) Mean() float64 {
+	return float64(bb.N) * bb.Alpha / (bb.Alpha + bb.Beta)
+}
+
+// Variance returns the variance
+func (bb *BetaBinomial) Variance() float64 {
+	n, ab := float64(bb.N), bb.Alpha+bb.Beta
+	return n * bb.Alpha * bb.Beta * (ab + n) / (ab * ab * (ab + 1))
+}
+
+// StdDev returns the standard deviation
+func (bb *BetaBinomial) StdDev() float64 {
+	return math.Sqrt(bb.Variance())
+}
+
+func logBeta(a, b float64) float64 {
+	la, _ := math.Lgamma(a)
+	lb, _ := math.Lgamma(b)
+	lab, _ := math.Lgamma(a + b)
+	return la + lb - lab
+}
+
+func logChoose(n, k float64) float64 {
+	ln, _ := math.Lgamma(n + 1)
+	lk, _ := math.Lgamma(k + 1)
+	lnk, _ := math.Lgamma(n - k + 1)
+	return ln - lk - lnk
+}
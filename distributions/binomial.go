@@ -0,0 +1,90 @@
+package distributions
+
+import (
+	"math"
+	"math/rand/v2"
+
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// Binomial represents the number of successes in N independent trials
+// with success probability P
+type Binomial struct {
+	N    int
+	P    float64
+	dist distuv.Binomial
+}
+
+// NewBinomial creates a new Binomial distribution
+func NewBinomial(n int, p float64) *Binomial {
+	return &Binomial{
+		N:    n,
+		P:    p,
+		dist: distuv.Binomial{N: float64(n), P: p},
+	}
+}
+
+// PMF returns the probability mass function at k
+func (b *Binomial) PMF(k int) float64 {
+	return b.dist.Prob(float64(k))
+}
+
+// LogPMF returns the log probability mass function at k
+func (b *Binomial) LogPMF(k int) float64 {
+	return b.dist.LogProb(float64(k))
+}
+
+// PDF returns the probability mass at x, which is zero for non-integer x
+func (b *Binomial) PDF(x float64) float64 {
+	return b.dist.Prob(x)
+}
+
+// LogPDF returns the log probability mass at x
+func (b *Binomial) LogPDF(x float64) float64 {
+	return b.dist.LogProb(x)
+}
+
+// CDF returns the cumulative distribution function at x
+func (b *Binomial) CDF(x float64) float64 {
+	return b.dist.CDF(x)
+}
+
+// Quantile returns the smallest integer k with CDF(k) >= p
+func (b *Binomial) Quantile(p float64) float64 {
+	cdf := func(k int) float64 { return b.CDF(float64(k)) }
+	return discreteQuantile(cdf, p, int(math.Ceil(b.Mean())), b.N)
+}
+
+// Sample generates a random sample
+func (b *Binomial) Sample() float64 {
+	return b.dist.Rand()
+}
+
+// SetSource sets the random source used for sampling; nil uses the global source
+func (b *Binomial) SetSource(src rand.Source) {
+	b.dist.Src = src
+}
+
+// SampleN generates n random samples
+func (b *Binomial) SampleN(n int) []float64 {
+	samples := make([]float64, n)
+	for i := 0; i < n; i++ {
+		samples[i] = b.Sample()
+	}
+	return samples
+}
+
+// Mean returns the expected value
+func (b *Binomial) Mean() float64 {
+	return b.dist.Mean()
+}
+
+// Variance returns the variance
+func (b *Binomial) Variance() float64 {
+	return b.dist.Variance()
+}
+
+// StdDev returns the standard deviation
+func (b *Binomial) StdDev() float64 {
+	return b.dist.StdDev()
+}
@@ -0,0 +1,123 @@
+package distributions
+
+import (
+	"math"
+	"testing"
+)
+
+// monteCarloComparison estimates P(b > a) and E[max(b - a, 0)] from seeded
+// draws, returning the standard errors of both estimates
+func monteCarloComparison(a, b Distribution, n int) (p, pErr, excess, excessErr float64) {
+	UseSource(a, NewSource(1))
+	UseSource(b, NewSource(2))
+	as, bs := a.SampleN(n), b.SampleN(n)
+
+	sumExcess, sumSquares := 0.0, 0.0
+	for i := range as {
+		if d := bs[i] - as[i]; d > 0 {
+			p++
+			sumExcess += d
+			sumSquares += d * d
+		}
+	}
+	p /= float64(n)
+	excess = sumExcess / float64(n)
+	return p, math.Sqrt(p * (1 - p) / float64(n)),
+		excess, math.Sqrt((sumSquares/float64(n) - excess*excess) / float64(n))
+}
+
+func TestProbabilityGreaterAgainstMonteCarlo(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b Distribution
+	}{
+		{"Beta closed-form sum", NewBeta(12, 90), NewBeta(18, 84)},
+		{"Beta sum over integer a", NewBeta(12, 90.5), NewBeta(18.5, 84)},
+		{"Beta quadrature", NewBeta(12.5, 90.5), NewBeta(18.5, 84.5)},
+		{"Beta quadrature above maxExactSumTerms", NewBeta(30001, 270000), NewBeta(30300, 269700)},
+		{"Beta quadrature with unbounded density", NewBeta(0.5, 40.5), NewBeta(2.5, 38.5)},
+		{"Beta posteriors", &BetaPosterior{NewBeta(3, 7)}, &BetaPosterior{NewBeta(5, 5)}},
+		{"Gamma", NewGamma(20, 4), NewGamma(30, 5)},
+		{"Gamma posteriors", &GammaPosterior{NewGamma(5, 2)}, &GammaPosterior{NewGamma(4, 2)}},
+		{"Normal", NewNormal(1, 2), NewNormal(1.5, 1)},
+		{"Normal conjugate", NewNormalConjugate(0, 1, 1), &NormalPosterior{Normal: NewNormal(-0.3, 0.5)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, ok := ProbabilityGreater(tt.a, tt.b)
+			if !ok {
+				t.Fatal("ProbabilityGreater found no exact path")
+			}
+			excess, ok := ExpectedExcess(tt.a, tt.b)
+			if !ok {
+				t.Fatal("ExpectedExcess found no exact path")
+			}
+
+			mcP, pErr, mcExcess, excessErr := monteCarloComparison(tt.a, tt.b, 200000)
+			if math.Abs(p-mcP) > 4*pErr+1e-9 {
+				t.Errorf("ProbabilityGreater = %.5f, Monte Carlo %.5f ± %.5f", p, mcP, pErr)
+			}
+			if math.Abs(excess-mcExcess) > 4*excessErr+1e-9 {
+				t.Errorf("ExpectedExcess = %.6g, Monte Carlo %.6g ± %.2g", excess, mcExcess, excessErr)
+			}
+
+			reverse, _ := ProbabilityGreater(tt.b, tt.a)
+			if math.Abs(p+reverse-1) > 1e-8 {
+				t.Errorf("P(b > a) + P(a > b) = %v, want 1", p+reverse)
+			}
+		})
+	}
+}
+
+func TestProbabilityGreaterIdenticalPosteriors(t *testing.T) {
+	tests := []struct {
+		name string
+		d    func() Distribution
+	}{
+		{"Beta sum", func() Distribution { return NewBeta(40, 960) }},
+		{"Beta quadrature", func() Distribution { return NewBeta(40.5, 960.5) }},
+		{"Beta above maxExactSumTerms", func() Distribution { return NewBeta(25000.5, 975000.5) }},
+		{"Gamma", func() Distribution { return NewGamma(7.5, 3) }},
+		{"Normal", func() Distribution { return NewNormal(2, 0.3) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := tt.d(), tt.d()
+			if p, _ := ProbabilityGreater(a, b); math.Abs(p-0.5) > 1e-6 {
+				t.Errorf("ProbabilityGreater = %v, want 0.5", p)
+			}
+			excess, _ := ExpectedExcess(a, b)
+			reverse, _ := ExpectedExcess(b, a)
+			if excess <= 0 || math.Abs(excess-reverse) > 1e-6*excess {
+				t.Errorf("ExpectedExcess = %v and %v, want equal and positive", excess, reverse)
+			}
+		})
+	}
+}
+
+func TestBetaSumMatchesQuadrature(t *testing.T) {
+	tests := []struct{ aa, ba, ab, bb float64 }{
+		{1, 1, 1, 1},
+		{12, 90, 18, 84},
+		{0.5, 10, 3, 8},
+		{200, 9800, 260, 9740},
+		{maxExactSumTerms, 1e6, maxExactSumTerms, 1e6},
+	}
+	for _, tt := range tests {
+		sum := betaProbGreaterSum(tt.aa, tt.ba, tt.ab, tt.bb)
+		integral := betaProbGreaterQuad(tt.aa, tt.ba, tt.ab, tt.bb)
+		if math.Abs(sum-integral) > 1e-6 {
+			t.Errorf("Beta(%v, %v) vs Beta(%v, %v): sum %.9f, quadrature %.9f",
+				tt.aa, tt.ba, tt.ab, tt.bb, sum, integral)
+		}
+	}
+}
+
+func TestProbabilityGreaterUnsupportedPair(t *testing.T) {
+	if _, ok := ProbabilityGreater(NewBeta(1, 1), NewNormal(0, 1)); ok {
+		t.Error("ProbabilityGreater(Beta, Normal) reported an exact path")
+	}
+	if _, ok := ExpectedExcess(NewGamma(1, 1), NewPoisson(1)); ok {
+		t.Error("ExpectedExcess(Gamma, Poisson) reported an exact path")
+	}
+}
@@ -0,0 +1,179 @@
+package distributions
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// Dirichlet represents a Dirichlet distribution over category probabilities
+type Dirichlet struct {
+	Alpha []float64
+	lbeta float64 // log of the multivariate Beta function of Alpha
+	src   rand.Source
+}
+
+// NewDirichlet creates a new Dirichlet distribution with one concentration
+// parameter per category
+func NewDirichlet(alpha []float64) *Dirichlet {
+	a := make([]float64, len(alpha))
+	copy(a, alpha)
+
+	total := 0.0
+	lbeta := 0.0
+	for _, v := range a {
+		lg, _ := math.Lgamma(v)
+		lbeta += lg
+		total += v
+	}
+	lg, _ := math.Lgamma(total)
+
+	return &Dirichlet{
+		Alpha: a,
+		lbeta: lbeta - lg,
+	}
+}
+
+// Dim returns the number of categories
+func (d *Dirichlet) Dim() int {
+	return len(d.Alpha)
+}
+
+// PDF returns the probability density function at x
+func (d *Dirichlet) PDF(x []float64) float64 {
+	return math.Exp(d.LogPDF(x))
+}
+
+// LogPDF returns the log probability density function at x
+func (d *Dirichlet) LogPDF(x []float64) float64 {
+	logProb := -d.lbeta
+	for i, a := range d.Alpha {
+		logProb += (a - 1) * math.Log(x[i])
+	}
+	return logProb
+}
+
+// Sample generates a random probability vector by normalizing Gamma draws
+func (d *Dirichlet) Sample() []float64 {
+	sample := make([]float64, len(d.Alpha))
+	total := 0.0
+	for i, a := range d.Alpha {
+		sample[i] = distuv.Gamma{Alpha: a, Beta: 1, Src: d.src}.Rand()
+		total += sample[i]
+	}
+	for i := range sample {
+		sample[i] /= total
+	}
+	return sample
+}
+
+// SetSource sets the random source used for sampling; nil uses the global source
+func (d *Dirichlet) SetSource(src rand.Source) {
+	d.src = src
+}
+
+// SampleN generates n random probability vectors
+func (d *Dirichlet) SampleN(n int) [][]float64 {
+	samples := make([][]float64, n)
+	for i := 0; i < n; i++ {
+		samples[i] = d.Sample()
+	}
+	return samples
+}
+
+// Mean returns the expected probability of each category
+func (d *Dirichlet) Mean() []float64 {
+	total := d.concentration()
+	mean := make([]float64, len(d.Alpha))
+	for i, a := range d.Alpha {
+		mean[i] = a / total
+	}
+	return mean
+}
+
+// Variance returns the variance of each category probability
+func (d *Dirichlet) Variance() []float64 {
+	total := d.concentration()
+	variance := make([]float64, len(d.Alpha))
+	for i, a := range d.Alpha {
+		variance[i] = a * (total - a) / (total * total * (total + 1))
+	}
+	return variance
+}
+
+// Update performs conjugate update with a multinomial likelihood, where
+// counts[i] is the number of observations falling in category i. It panics
+// if there is not one count per category or a count is negative or NaN.
+func (d *Dirichlet) Update(counts []float64) MultivariatePosterior {
+	if len(counts) != len(d.Alpha) {
+		panic(fmt.Sprintf("distributions: Dirichlet update has %d counts, want %d", len(counts), len(d.Alpha)))
+	}
+	alpha := make([]float64, len(d.Alpha))
+	for i, a := range d.Alpha {
+		if !(counts[i] >= 0) {
+			panic(fmt.Sprintf("distributions: Dirichlet update count %d is %v", i, counts[i]))
+		}
+		alpha[i] = a + counts[i]
+	}
+	post := NewDirichlet(alpha)
+	post.SetSource(d.src)
+	return &DirichletPosterior{Dirichlet: post}
+}
+
+// UpdateSingle updates with a single observation of the given category. It
+// panics if the category is out of range.
+func (d *Dirichlet) UpdateSingle(category int) MultivariatePosterior {
+	if category < 0 || category >= len(d.Alpha) {
+		panic(fmt.Sprintf("distributions: Dirichlet category %d out of range [0, %d)", category, len(d.Alpha)))
+	}
+	counts := make([]float64, len(d.Alpha))
+	counts[category] = 1
+	return d.Update(counts)
+}
+
+func (d *Dirichlet) concentration() float64 {
+	total := 0.0
+	for _, a := range d.Alpha {
+		total += a
+	}
+	return total
+}
+
+// DirichletPosterior represents a Dirichlet posterior distribution
+type DirichletPosterior struct {
+	*Dirichlet
+}
+
+// Marginal returns the Beta posterior of the i-th category probability
+func (dp *DirichletPosterior) Marginal(i int) Posterior {
+	marginal := NewBeta(dp.Alpha[i], dp.concentration()-dp.Alpha[i])
+	marginal.SetSource(dp.src)
+	return &BetaPosterior{Beta: marginal}
+}
+
+// CredibleIntervals returns the marginal credible interval of each category probability
+func (dp *DirichletPosterior) CredibleIntervals(confidence float64) [][2]float64 {
+	intervals := make([][2]float64, len(dp.Alpha))
+	for i := range dp.Alpha {
+		intervals[i][0], intervals[i][1] = dp.Marginal(i).CredibleInterval(confidence)
+	}
+	return intervals
+}
+
+// MAP returns the maximum a posteriori estimate, falling back to the mean
+// when some concentration parameters are at most one and the mode lies on
+// the boundary of the simplex
+func (dp *DirichletPosterior) MAP() []float64 {
+	k := float64(len(dp.Alpha))
+	total := dp.concentration()
+	mode := make([]float64, len(dp.Alpha))
+	for i, a := range dp.Alpha {
+		if a <= 1 {
+			return dp.Mean()
+		}
+		mode[i] = (a - 1) / (total - k)
+	}
+	return mode
+}
@@ -0,0 +1,49 @@
+package distributions
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestDirichletUpdate(t *testing.T) {
+	post := NewDirichlet([]float64{1, 2, 3}).Update([]float64{4, 0, 1.5})
+	want := []float64{5, 2, 4.5}
+	for i, a := range post.(*DirichletPosterior).Alpha {
+		if a != want[i] {
+			t.Errorf("alpha[%d] = %v, want %v", i, a, want[i])
+		}
+	}
+
+	single := NewDirichlet([]float64{1, 1}).UpdateSingle(1).(*DirichletPosterior)
+	if single.Alpha[0] != 1 || single.Alpha[1] != 2 {
+		t.Errorf("UpdateSingle(1) alpha = %v, want [1 2]", single.Alpha)
+	}
+}
+
+func TestDirichletUpdateRejectsInvalidCounts(t *testing.T) {
+	prior := NewDirichlet([]float64{1, 1, 1})
+	tests := []struct {
+		name    string
+		update  func()
+		message string
+	}{
+		{"too few counts", func() { prior.Update([]float64{1, 2}) }, "has 2 counts, want 3"},
+		{"too many counts", func() { prior.Update([]float64{1, 2, 3, 4}) }, "has 4 counts, want 3"},
+		{"negative count", func() { prior.Update([]float64{1, -1, 0}) }, "count 1 is -1"},
+		{"NaN count", func() { prior.Update([]float64{math.NaN(), 0, 0}) }, "count 0 is NaN"},
+		{"negative category", func() { prior.UpdateSingle(-1) }, "category -1 out of range"},
+		{"category past the end", func() { prior.UpdateSingle(3) }, "category 3 out of range"},
+	}
+	for _, tt := range tests {
+		func() {
+			defer func() {
+				r := recover()
+				if msg, ok := r.(string); !ok || !strings.Contains(msg, tt.message) {
+					t.Errorf("%s: panic %v, want message containing %q", tt.name, r, tt.message)
+				}
+			}()
+			tt.update()
+		}()
+	}
+}
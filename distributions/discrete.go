@@ -0,0 +1,45 @@
+package distributions
+
+import "math"
+
+// discreteQuantile returns the smallest non-negative integer k with cdf(k) >= p.
+// guess is a starting point for the search (typically the mean) and max is the
+// upper end of the support, or a negative value for unbounded support.
+func discreteQuantile(cdf func(k int) float64, p float64, guess, max int) float64 {
+	if p <= 0 {
+		return 0
+	}
+	if p >= 1 {
+		if max < 0 {
+			return math.Inf(1)
+		}
+		return float64(max)
+	}
+
+	// Expand the upper bracket until it covers p
+	hi := guess
+	if hi < 1 {
+		hi = 1
+	}
+	for cdf(hi) < p {
+		if max >= 0 && hi >= max {
+			return float64(max)
+		}
+		hi *= 2
+		if max >= 0 && hi > max {
+			hi = max
+		}
+	}
+
+	// Binary search for the smallest k in [0, hi] with cdf(k) >= p
+	lo := -1
+	for hi-lo > 1 {
+		mid := lo + (hi-lo)/2
+		if cdf(mid) >= p {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+	return float64(hi)
+}
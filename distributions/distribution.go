@@ -1,8 +1,11 @@
 package distributions
 
 import (
+	"math"
+	"math/rand/v2"
 	"slices"
 
+	"github.com/MyVueCodeHub/myvue-bayes/kde"
 	"gonum.org/v1/gonum/stat"
 )
 
@@ -50,6 +53,49 @@ type ContinuousDistribution interface {
 	Entropy() float64
 }
 
+// SequentialPosterior is a posterior that can serve as the prior for the next
+// batch of data, so streaming pipelines never need to retain raw observations.
+// For every conjugate family in this package, updating batch by batch yields
+// the same posterior as a single update over all of the data; results are
+// bit-for-bit identical for count data and agree to floating-point rounding
+// for continuous data.
+type SequentialPosterior interface {
+	Posterior
+	Prior
+}
+
+var (
+	_ SequentialPosterior = (*BetaPosterior)(nil)
+	_ SequentialPosterior = (*GammaPosterior)(nil)
+	_ SequentialPosterior = (*NormalPosterior)(nil)
+	_ SequentialPosterior = (*NormalInverseGammaPosterior)(nil)
+	_ MultivariatePrior   = (*DirichletPosterior)(nil)
+)
+
+// Seeder is implemented by distributions whose random draws can be driven by a
+// caller-supplied source, making sampling reproducible. Posteriors returned by
+// Update share the random source of the prior they were updated from.
+// A rand.Source is not safe for concurrent use.
+type Seeder interface {
+	// SetSource sets the random source used for sampling; nil uses the global source
+	SetSource(src rand.Source)
+}
+
+// NewSource returns a deterministic random source for the given seed
+func NewSource(seed uint64) rand.Source {
+	return rand.NewPCG(seed, seed)
+}
+
+// UseSource sets the random source of d if it implements Seeder and reports
+// whether it did
+func UseSource(d any, src rand.Source) bool {
+	s, ok := d.(Seeder)
+	if ok {
+		s.SetSource(src)
+	}
+	return ok
+}
+
 // DiscreteDistribution extends Distribution for discrete random variables
 type DiscreteDistribution interface {
 	Distribution
@@ -86,6 +132,62 @@ type Posterior interface {
 	HPD(confidence float64) (lower, upper float64)
 }
 
+// MultivariateDistribution defines the interface for vector-valued distributions
+type MultivariateDistribution interface {
+	// Dim returns the dimension of the random vector
+	Dim() int
+
+	// PDF returns the probability density function at x
+	PDF(x []float64) float64
+
+	// LogPDF returns the log probability density function at x
+	LogPDF(x []float64) float64
+
+	// Sample generates a random sample from the distribution
+	Sample() []float64
+
+	// SampleN generates n random samples from the distribution
+	SampleN(n int) [][]float64
+
+	// Mean returns the expected value of each component
+	Mean() []float64
+
+	// Variance returns the variance of each component
+	Variance() []float64
+}
+
+// MultivariatePrior represents a vector-valued prior that can be updated
+type MultivariatePrior interface {
+	MultivariateDistribution
+
+	// Update returns the posterior distribution given per-category counts
+	Update(counts []float64) MultivariatePosterior
+
+	// UpdateSingle returns the posterior distribution given a single observed category
+	UpdateSingle(category int) MultivariatePosterior
+}
+
+// MultivariatePosterior represents a vector-valued posterior distribution
+type MultivariatePosterior interface {
+	MultivariateDistribution
+
+	// Marginal returns the posterior distribution of the i-th component
+	Marginal(i int) Posterior
+
+	// CredibleIntervals returns the marginal credible interval of each component
+	CredibleIntervals(confidence float64) [][2]float64
+
+	// MAP returns the maximum a posteriori estimate
+	MAP() []float64
+}
+
+// Predictive is implemented by posteriors that can describe future observations
+// rather than only the parameter
+type Predictive interface {
+	// PosteriorPredictive returns the distribution of the total of the next n observations
+	PosteriorPredictive(n int) Distribution
+}
+
 // Summary provides a statistical summary of a distribution
 type Summary struct {
 	Mean     float64
@@ -96,36 +198,107 @@ type Summary struct {
 	CI95     [2]float64
 	CI99     [2]float64
 	Samples  []float64
+
+	// Intervals maps each requested credible level to its interval
+	Intervals map[float64][2]float64
+	// Quantiles maps each requested probability to its sample quantile
+	Quantiles map[float64]float64
+}
+
+// IntervalType selects how credible intervals are computed from samples
+type IntervalType int
+
+const (
+	// EqualTailed intervals leave the same probability mass in each tail
+	EqualTailed IntervalType = iota
+	// HighestDensity intervals are the narrowest interval with the requested mass
+	HighestDensity
+)
+
+// SummaryOptions configures ComputeSummaryWithOptions
+type SummaryOptions struct {
+	// CredibleLevels lists the credible levels to compute, e.g. 0.8 or 0.9
+	CredibleLevels []float64
+	// IntervalType selects equal-tailed or highest-density intervals
+	IntervalType IntervalType
+	// Quantiles lists additional probabilities whose quantiles are reported
+	Quantiles []float64
+	// Weights optionally weights each sample, e.g. importance weights
+	Weights []float64
+	// RetainSamples keeps the input samples in the summary
+	RetainSamples bool
+}
+
+// DefaultSummaryOptions returns the options used by ComputeSummary: equal-tailed
+// 95% and 99% intervals with the samples retained
+func DefaultSummaryOptions() SummaryOptions {
+	return SummaryOptions{
+		CredibleLevels: []float64{0.95, 0.99},
+		IntervalType:   EqualTailed,
+		RetainSamples:  true,
+	}
 }
 
 // ComputeSummary generates a statistical summary from samples
 func ComputeSummary(samples []float64) Summary {
-	sort := make([]float64, len(samples))
-	copy(sort, samples)
-
-	slices.Sort(sort)
-
-	return Summary{
-		Mean:     stat.Mean(samples, nil),
-		Median:   stat.Quantile(0.5, stat.Empirical, sort, nil),
-		Mode:     estimateMode(samples),
-		Variance: stat.Variance(samples, nil),
-		StdDev:   stat.StdDev(samples, nil),
-		CI95: [2]float64{
-			stat.Quantile(0.025, stat.Empirical, sort, nil),
-			stat.Quantile(0.975, stat.Empirical, sort, nil),
-		},
-		CI99: [2]float64{
-			stat.Quantile(0.005, stat.Empirical, sort, nil),
-			stat.Quantile(0.995, stat.Empirical, sort, nil),
-		},
-		Samples: samples,
+	return ComputeSummaryWithOptions(samples, DefaultSummaryOptions())
+}
+
+// ComputeSummaryWithOptions generates a statistical summary from samples with
+// configurable credible levels, interval type, quantiles and sample weights.
+// CI95 and CI99 are filled when those levels are requested.
+func ComputeSummaryWithOptions(samples []float64, opts SummaryOptions) Summary {
+	sorted := make([]float64, len(samples))
+	copy(sorted, samples)
+
+	var sortedWeights []float64
+	if opts.Weights != nil {
+		sortedWeights = make([]float64, len(opts.Weights))
+		copy(sortedWeights, opts.Weights)
+		stat.SortWeighted(sorted, sortedWeights)
+	} else {
+		slices.Sort(sorted)
+	}
+
+	summary := Summary{
+		Mean:      stat.Mean(samples, opts.Weights),
+		Median:    stat.Quantile(0.5, stat.Empirical, sorted, sortedWeights),
+		Mode:      estimateMode(samples, opts.Weights),
+		Variance:  stat.Variance(samples, opts.Weights),
+		StdDev:    stat.StdDev(samples, opts.Weights),
+		Intervals: make(map[float64][2]float64, len(opts.CredibleLevels)),
+		Quantiles: make(map[float64]float64, len(opts.Quantiles)),
+	}
+
+	for _, level := range opts.CredibleLevels {
+		var interval [2]float64
+		if opts.IntervalType == HighestDensity {
+			interval[0], interval[1] = sortedHPD(sorted, sortedWeights, level)
+		} else {
+			tail := (1 - level) / 2
+			interval[0] = stat.Quantile(tail, stat.Empirical, sorted, sortedWeights)
+			interval[1] = stat.Quantile(1-tail, stat.Empirical, sorted, sortedWeights)
+		}
+		summary.Intervals[level] = interval
+	}
+	summary.CI95 = summary.Intervals[0.95]
+	summary.CI99 = summary.Intervals[0.99]
+
+	for _, p := range opts.Quantiles {
+		summary.Quantiles[p] = stat.Quantile(p, stat.Empirical, sorted, sortedWeights)
 	}
+
+	if opts.RetainSamples {
+		summary.Samples = samples
+	}
+
+	return summary
 }
 
-func estimateMode(samples []float64) float64 {
-	// Simple kernel density estimation for mode
-	//bandwidth := 1.06 * stat.StdDev(samples, nil) * math.Pow(float64(len(samples)), -0.2)
-	// Simplified implementation - in production use more sophisticated KDE
-	return stat.Mean(samples, nil) // Placeholder
+// estimateMode locates the peak of a Gaussian kernel density estimate
+func estimateMode(samples, weights []float64) float64 {
+	if len(samples) == 0 {
+		return math.NaN()
+	}
+	return kde.New(samples, weights, kde.Gaussian, kde.Silverman).Mode()
 }
@@ -0,0 +1,156 @@
+package distributions
+
+import (
+	"math"
+	"math/rand/v2"
+
+	"gonum.org/v1/gonum/mathext"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// Gamma represents a Gamma distribution parameterized by shape and rate
+type Gamma struct {
+	Alpha float64 // shape
+	Beta  float64 // rate
+	dist  distuv.Gamma
+}
+
+// NewGamma creates a new Gamma distribution with the given shape and rate
+func NewGamma(alpha, beta float64) *Gamma {
+	return &Gamma{
+		Alpha: alpha,
+		Beta:  beta,
+		dist:  distuv.Gamma{Alpha: alpha, Beta: beta},
+	}
+}
+
+// PDF returns the probability density function at x
+func (g *Gamma) PDF(x float64) float64 {
+	return g.dist.Prob(x)
+}
+
+// LogPDF returns the log probability density function at x
+func (g *Gamma) LogPDF(x float64) float64 {
+	return g.dist.LogProb(x)
+}
+
+// CDF returns the cumulative distribution function at x
+func (g *Gamma) CDF(x float64) float64 {
+	return g.dist.CDF(x)
+}
+
+// Quantile returns the inverse CDF at probability p
+func (g *Gamma) Quantile(p float64) float64 {
+	return g.dist.Quantile(p)
+}
+
+// Sample generates a random sample
+func (g *Gamma) Sample() float64 {
+	return g.dist.Rand()
+}
+
+// SetSource sets the random source used for sampling; nil uses the global source
+func (g *Gamma) SetSource(src rand.Source) {
+	g.dist.Src = src
+}
+
+// SampleN generates n random samples
+func (g *Gamma) SampleN(n int) []float64 {
+	samples := make([]float64, n)
+	for i := 0; i < n; i++ {
+		samples[i] = g.Sample()
+	}
+	return samples
+}
+
+// Mean returns the expected value
+func (g *Gamma) Mean() float64 {
+	return g.dist.Mean()
+}
+
+// Variance returns the variance
+func (g *Gamma) Variance() float64 {
+	return g.dist.Variance()
+}
+
+// StdDev returns the standard deviation
+func (g *Gamma) StdDev() float64 {
+	return g.dist.StdDev()
+}
+
+// Mode returns the mode of the distribution
+func (g *Gamma) Mode() []float64 {
+	if g.Alpha >= 1 {
+		return []float64{(g.Alpha - 1) / g.Beta}
+	}
+	return []float64{0}
+}
+
+// Median returns the median
+func (g *Gamma) Median() float64 {
+	return g.Quantile(0.5)
+}
+
+// Entropy returns the differential entropy
+func (g *Gamma) Entropy() float64 {
+	lg, _ := math.Lgamma(g.Alpha)
+	return g.Alpha - math.Log(g.Beta) + lg + (1-g.Alpha)*mathext.Digamma(g.Alpha)
+}
+
+// Update performs Bayesian update with Poisson likelihood, where each
+// observation is a non-negative event count for one unit of exposure
+func (g *Gamma) Update(data []float64) Posterior {
+	return g.UpdateStatistics(g.Statistics(data))
+}
+
+// Statistics summarizes observed counts
+func (g *Gamma) Statistics(data []float64) SufficientStatistics {
+	return ComputeStatistics(data)
+}
+
+// UpdateStatistics performs Bayesian update from the number of exposure units
+// (N) and the total count (Sum)
+func (g *Gamma) UpdateStatistics(stats SufficientStatistics) Posterior {
+	return g.posterior(g.Alpha+stats.Sum, g.Beta+stats.N)
+}
+
+// UpdateSingle updates with a single observed count
+func (g *Gamma) UpdateSingle(observation float64) Posterior {
+	return g.posterior(g.Alpha+observation, g.Beta+1)
+}
+
+// posterior returns a GammaPosterior that shares the receiver's random source
+func (g *Gamma) posterior(alpha, beta float64) *GammaPosterior {
+	post := NewGamma(alpha, beta)
+	post.SetSource(g.dist.Src)
+	return &GammaPosterior{Gamma: post}
+}
+
+// GammaPosterior represents a Gamma posterior distribution
+type GammaPosterior struct {
+	*Gamma
+}
+
+// CredibleInterval returns the credible interval
+func (gp *GammaPosterior) CredibleInterval(confidence float64) (lower, upper float64) {
+	alpha := (1 - confidence) / 2
+	return gp.Quantile(alpha), gp.Quantile(1 - alpha)
+}
+
+// MAP returns the maximum a posteriori estimate
+func (gp *GammaPosterior) MAP() float64 {
+	return gp.Mode()[0]
+}
+
+// HPD returns the highest posterior density interval
+func (gp *GammaPosterior) HPD(confidence float64) (lower, upper float64) {
+	return HPDInterval(gp, confidence)
+}
+
+// PosteriorPredictive returns the Negative-Binomial distribution of the total
+// count over the next n units of exposure
+func (gp *GammaPosterior) PosteriorPredictive(n int) Distribution {
+	predictive := NewNegativeBinomial(gp.Alpha, gp.Beta/(gp.Beta+float64(n)))
+	predictive.SetSource(gp.dist.Src)
+	return predictive
+}
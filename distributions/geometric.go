@@ -0,0 +1,110 @@
+package distributions
+
+import (
+	"math"
+	"math/rand/v2"
+)
+
+// Geometric represents the number of failures before the first success
+// in independent trials with success probability P
+type Geometric struct {
+	P   float64
+	src rand.Source
+}
+
+// NewGeometric creates a new Geometric distribution
+func NewGeometric(p float64) *Geometric {
+	return &Geometric{P: p}
+}
+
+// PMF returns the probability mass function at k
+func (g *Geometric) PMF(k int) float64 {
+	return math.Exp(g.LogPMF(k))
+}
+
+// LogPMF returns the log probability mass function at k
+func (g *Geometric) LogPMF(k int) float64 {
+	if k < 0 {
+		return math.Inf(-1)
+	}
+	if g.P == 1 {
+		// Success is certain, so every mass is at zero; avoids 0 * log(0)
+		if k == 0 {
+			return 0
+		}
+		return math.Inf(-1)
+	}
+	return math.Log(g.P) + float64(k)*math.Log1p(-g.P)
+}
+
+// PDF returns the probability mass at x, which is zero for non-integer x
+func (g *Geometric) PDF(x float64) float64 {
+	if x != math.Floor(x) {
+		return 0
+	}
+	return g.PMF(int(x))
+}
+
+// LogPDF returns the log probability mass at x
+func (g *Geometric) LogPDF(x float64) float64 {
+	if x != math.Floor(x) {
+		return math.Inf(-1)
+	}
+	return g.LogPMF(int(x))
+}
+
+// CDF returns the cumulative distribution function at x
+func (g *Geometric) CDF(x float64) float64 {
+	if x < 0 {
+		return 0
+	}
+	return -math.Expm1((math.Floor(x) + 1) * math.Log1p(-g.P))
+}
+
+// Quantile returns the smallest integer k with CDF(k) >= p
+func (g *Geometric) Quantile(p float64) float64 {
+	cdf := func(k int) float64 { return g.CDF(float64(k)) }
+	return discreteQuantile(cdf, p, int(math.Ceil(g.Mean())), -1)
+}
+
+// Sample generates a random sample by inverting the CDF
+func (g *Geometric) Sample() float64 {
+	if g.P >= 1 {
+		return 0
+	}
+	u := rand.Float64()
+	if g.src != nil {
+		u = rand.New(g.src).Float64()
+	}
+	// 1-u is in (0, 1], avoiding log(0)
+	return math.Floor(math.Log(1-u) / math.Log1p(-g.P))
+}
+
+// SetSource sets the random source used for sampling; nil uses the global source
+func (g *Geometric) SetSource(src rand.Source) {
+	g.src = src
+}
+
+// SampleN generates n random samples
+func (g *Geometric) SampleN(n int) []float64 {
+	samples := make([]float64, n)
+	for i := 0; i < n; i++ {
+		samples[i] = g.Sample()
+	}
+	return samples
+}
+
+// Mean returns the expected value
+func (g *Geometric) Mean() float64 {
+	return (1 - g.P) / g.P
+}
+
+// Variance returns the variance
+func (g *Geometric) Variance() float64 {
+	return (1 - g.P) / (g.P * g.P)
+}
+
+// StdDev returns the standard deviation
+func (g *Geometric) StdDev() float64 {
+	return math.Sqrt(g.Variance())
+}
@@ -0,0 +1,93 @@
+package distributions
+
+import (
+	"math"
+	"slices"
+)
+
+// HPDInterval returns the highest density interval of a unimodal distribution:
+// the narrowest interval containing the given probability mass. It searches over
+// the mass left in the lower tail using the distribution's Quantile, so it also
+// handles densities that peak at a boundary of their support.
+func HPDInterval(d Distribution, confidence float64) (lower, upper float64) {
+	width := func(tail float64) float64 {
+		return d.Quantile(tail+confidence) - d.Quantile(tail)
+	}
+
+	// Golden-section search over the lower tail mass in [0, 1-confidence]
+	const invPhi = 0.6180339887498949
+	a, b := 0.0, 1-confidence
+	c := b - invPhi*(b-a)
+	e := a + invPhi*(b-a)
+	wc, we := width(c), width(e)
+	for i := 0; i < 100 && b-a > 1e-10; i++ {
+		if wc < we {
+			b, e, we = e, c, wc
+			c = b - invPhi*(b-a)
+			wc = width(c)
+		} else {
+			a, c, wc = c, e, we
+			e = a + invPhi*(b-a)
+			we = width(e)
+		}
+	}
+
+	tail := (a + b) / 2
+	// Snap to the boundary when the density is monotone there
+	if width(0) <= width(tail) {
+		tail = 0
+	} else if width(1-confidence) <= width(tail) {
+		tail = 1 - confidence
+	}
+	return d.Quantile(tail), d.Quantile(tail + confidence)
+}
+
+// SampleHPD returns the narrowest interval containing the given fraction of the
+// samples, an estimate of the highest density interval from Monte Carlo draws
+func SampleHPD(samples []float64, confidence float64) (lower, upper float64) {
+	sorted := make([]float64, len(samples))
+	copy(sorted, samples)
+	slices.Sort(sorted)
+	return sortedHPD(sorted, nil, confidence)
+}
+
+// sortedHPD returns the narrowest interval of sorted samples holding at least
+// the given fraction of the total weight. weights may be nil.
+func sortedHPD(sorted, weights []float64, confidence float64) (lower, upper float64) {
+	n := len(sorted)
+	if n == 0 {
+		return math.NaN(), math.NaN()
+	}
+
+	weight := func(i int) float64 {
+		if weights == nil {
+			return 1
+		}
+		return weights[i]
+	}
+	total := 0.0
+	for i := 0; i < n; i++ {
+		total += weight(i)
+	}
+	target := confidence * total
+
+	// Slide a window over the sorted samples, keeping the narrowest one
+	// whose weight reaches the target
+	lower, upper = sorted[0], sorted[n-1]
+	mass := 0.0
+	j := 0
+	for i := 0; i < n; i++ {
+		for j < n && mass < target {
+			mass += weight(j)
+			j++
+		}
+		if mass < target {
+			break
+		}
+		if sorted[j-1]-sorted[i] < upper-lower {
+			lower, upper = sorted[i], sorted[j-1]
+		}
+		mass -= weight(i)
+	}
+	return lower, upper
+}
@@ -0,0 +1,111 @@
+package distributions
+
+import (
+	"math"
+	"math/rand/v2"
+
+	"gonum.org/v1/gonum/mathext"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// NegativeBinomial represents the number of failures before the R-th success
+// in independent trials with success probability P. R may be non-integer,
+// in which case it is the Gamma-Poisson mixture with shape R.
+type NegativeBinomial struct {
+	R   float64
+	P   float64
+	src rand.Source
+}
+
+// NewNegativeBinomial creates a new NegativeBinomial distribution
+func NewNegativeBinomial(r, p float64) *NegativeBinomial {
+	return &NegativeBinomial{
+		R: r,
+		P: p,
+	}
+}
+
+// PMF returns the probability mass function at k
+func (nb *NegativeBinomial) PMF(k int) float64 {
+	return math.Exp(nb.LogPMF(k))
+}
+
+// LogPMF returns the log probability mass function at k
+func (nb *NegativeBinomial) LogPMF(k int) float64 {
+	if k < 0 {
+		return math.Inf(-1)
+	}
+	kf := float64(k)
+	lgKR, _ := math.Lgamma(kf + nb.R)
+	lgK, _ := math.Lgamma(kf + 1)
+	lgR, _ := math.Lgamma(nb.R)
+	return lgKR - lgK - lgR + nb.R*math.Log(nb.P) + kf*math.Log1p(-nb.P)
+}
+
+// PDF returns the probability mass at x, which is zero for non-integer x
+func (nb *NegativeBinomial) PDF(x float64) float64 {
+	if x != math.Floor(x) {
+		return 0
+	}
+	return nb.PMF(int(x))
+}
+
+// LogPDF returns the log probability mass at x
+func (nb *NegativeBinomial) LogPDF(x float64) float64 {
+	if x != math.Floor(x) {
+		return math.Inf(-1)
+	}
+	return nb.LogPMF(int(x))
+}
+
+// CDF returns the cumulative distribution function at x
+func (nb *NegativeBinomial) CDF(x float64) float64 {
+	if x < 0 {
+		return 0
+	}
+	return mathext.RegIncBeta(nb.R, math.Floor(x)+1, nb.P)
+}
+
+// Quantile returns the smallest integer k with CDF(k) >= p
+func (nb *NegativeBinomial) Quantile(p float64) float64 {
+	cdf := func(k int) float64 { return nb.CDF(float64(k)) }
+	return discreteQuantile(cdf, p, int(math.Ceil(nb.Mean())), -1)
+}
+
+// Sample generates a random sample as a Poisson draw with Gamma-distributed rate
+func (nb *NegativeBinomial) Sample() float64 {
+	if nb.P >= 1 {
+		return 0
+	}
+	lambda := distuv.Gamma{Alpha: nb.R, Beta: nb.P / (1 - nb.P), Src: nb.src}.Rand()
+	return distuv.Poisson{Lambda: lambda, Src: nb.src}.Rand()
+}
+
+// SetSource sets the random source used for sampling; nil uses the global source
+func (nb *NegativeBinomial) SetSource(src rand.Source) {
+	nb.src = src
+}
+
+// SampleN generates n random samples
+func (nb *NegativeBinomial) SampleN(n int) []float64 {
+	samples := make([]float64, n)
+	for i := 0; i < n; i++ {
+		samples[i] = nb.Sample()
+	}
+	return samples
+}
+
+// Mean returns the expected value
+func (nb *NegativeBinomial) Mean() float64 {
+	return nb.R * (1 - nb.P) / nb.P
+}
+
+// Variance returns the variance
+func (nb *NegativeBinomial) Variance() float64 {
+	return nb.R * (1 - nb.P) / (nb.P * nb.P)
+}
+
+// StdDev returns the standard deviation
+func (nb *NegativeBinomial) StdDev() float64 {
+	return math.Sqrt(nb.Variance())
+}
@@ -2,6 +2,7 @@ package distributions
 
 import (
 	"math"
+	"math/rand/v2"
 
 	"gonum.org/v1/gonum/stat/distuv"
 )
@@ -47,6 +48,11 @@ func (n *Normal) Sample() float64 {
 	return n.dist.Rand()
 }
 
+// SetSource sets the random source used for sampling; nil uses the global source
+func (n *Normal) SetSource(src rand.Source) {
+	n.dist.Src = src
+}
+
 // SampleN generates n random samples
 func (n *Normal) SampleN(nSamples int) []float64 {
 	samples := make([]float64, nSamples)
@@ -102,23 +108,30 @@ func NewNormalConjugate(mu, sigma, knownVariance float64) *NormalConjugate {
 
 // Update performs conjugate update with Normal likelihood
 func (nc *NormalConjugate) Update(data []float64) Posterior {
-	n := float64(len(data))
-	sumX := 0.0
-	for _, x := range data {
-		sumX += x
-	}
-	xBar := sumX / n
+	return nc.UpdateStatistics(nc.Statistics(data))
+}
+
+// Statistics summarizes raw observations
+func (nc *NormalConjugate) Statistics(data []float64) SufficientStatistics {
+	return ComputeStatistics(data)
+}
 
+// UpdateStatistics performs conjugate update from the number of observations
+// (N) and their sum (Sum)
+func (nc *NormalConjugate) UpdateStatistics(stats SufficientStatistics) Posterior {
 	// Conjugate update formulas
 	tau0 := 1.0 / (nc.Sigma * nc.Sigma)
 	tau := 1.0 / nc.KnownVariance
 
-	tauNew := tau0 + n*tau
-	muNew := (tau0*nc.Mu + n*tau*xBar) / tauNew
+	tauNew := tau0 + stats.N*tau
+	muNew := (tau0*nc.Mu + tau*stats.Sum) / tauNew
 	sigmaNew := math.Sqrt(1.0 / tauNew)
 
+	post := NewNormal(muNew, sigmaNew)
+	post.SetSource(nc.dist.Src)
 	return &NormalPosterior{
-		Normal: NewNormal(muNew, sigmaNew),
+		Normal:        post,
+		KnownVariance: nc.KnownVariance,
 	}
 }
 
@@ -130,6 +143,35 @@ func (nc *NormalConjugate) UpdateSingle(observation float64) Posterior {
 // NormalPosterior represents a Normal posterior distribution
 type NormalPosterior struct {
 	*Normal
+	KnownVariance float64 // observation variance of the likelihood
+}
+
+// Update performs conjugate update treating the posterior as the prior for new data
+func (np *NormalPosterior) Update(data []float64) Posterior {
+	return np.asPrior().Update(data)
+}
+
+// UpdateSingle updates with a single observation
+func (np *NormalPosterior) UpdateSingle(observation float64) Posterior {
+	return np.asPrior().UpdateSingle(observation)
+}
+
+// Statistics summarizes raw observations
+func (np *NormalPosterior) Statistics(data []float64) SufficientStatistics {
+	return ComputeStatistics(data)
+}
+
+// UpdateStatistics performs conjugate update from summarized observations
+func (np *NormalPosterior) UpdateStatistics(stats SufficientStatistics) Posterior {
+	return np.asPrior().UpdateStatistics(stats)
+}
+
+// asPrior returns the conjugate prior equivalent to the posterior
+func (np *NormalPosterior) asPrior() *NormalConjugate {
+	return &NormalConjugate{
+		Normal:        np.Normal,
+		KnownVariance: np.KnownVariance,
+	}
 }
 
 // CredibleInterval returns the credible interval
@@ -148,3 +190,14 @@ func (np *NormalPosterior) HPD(confidence float64) (lower, upper float64) {
 	// For Normal distribution, HPD equals credible interval
 	return np.CredibleInterval(confidence)
 }
+
+// PosteriorPredictive returns the Normal distribution of the sum of the next n
+// observations, whose variance includes both observation noise and the
+// remaining uncertainty in the mean
+func (np *NormalPosterior) PosteriorPredictive(n int) Distribution {
+	nf := float64(n)
+	variance := nf*np.KnownVariance + nf*nf*np.Variance()
+	predictive := NewNormal(nf*np.Mu, math.Sqrt(variance))
+	predictive.SetSource(np.dist.Src)
+	return predictive
+}
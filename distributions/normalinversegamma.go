@@ -0,0 +1,116 @@
+package distributions
+
+import "math"
+
+// NormalInverseGamma implements the conjugate prior for a Normal likelihood with
+// unknown mean and variance:
+//
+//	sigma² ~ InverseGamma(Alpha, Beta)
+//	mu | sigma² ~ Normal(Mu, sigma²/Lambda)
+//
+// As a Distribution it describes the marginal distribution of the mean, which is
+// Student's t, so it can be compared directly with other priors in an A/B test.
+type NormalInverseGamma struct {
+	*StudentT
+	Mu     float64 // prior mean
+	Lambda float64 // pseudo-observations backing the prior mean
+	Alpha  float64 // shape of the variance prior
+	Beta   float64 // scale of the variance prior
+}
+
+// NewNormalInverseGamma creates a Normal-Inverse-Gamma prior
+func NewNormalInverseGamma(mu, lambda, alpha, beta float64) *NormalInverseGamma {
+	return &NormalInverseGamma{
+		StudentT: NewStudentT(mu, math.Sqrt(beta/(alpha*lambda)), 2*alpha),
+		Mu:       mu,
+		Lambda:   lambda,
+		Alpha:    alpha,
+		Beta:     beta,
+	}
+}
+
+// Update performs conjugate update with a Normal likelihood of unknown variance
+func (nig *NormalInverseGamma) Update(data []float64) Posterior {
+	return nig.UpdateStatistics(nig.Statistics(data))
+}
+
+// Statistics summarizes raw observations
+func (nig *NormalInverseGamma) Statistics(data []float64) SufficientStatistics {
+	return ComputeStatistics(data)
+}
+
+// UpdateStatistics performs conjugate update from the number of observations
+// (N), their sum (Sum) and their sum of squared deviations from the mean (M2)
+func (nig *NormalInverseGamma) UpdateStatistics(stats SufficientStatistics) Posterior {
+	if stats.N == 0 {
+		return nig.posterior(nig.Mu, nig.Lambda, nig.Alpha, nig.Beta)
+	}
+
+	n := stats.N
+	xBar := stats.Mean()
+	ss := stats.SumSquaredDeviations()
+
+	lambdaNew := nig.Lambda + n
+	muNew := (nig.Lambda*nig.Mu + n*xBar) / lambdaNew
+	alphaNew := nig.Alpha + n/2
+	betaNew := nig.Beta + ss/2 + nig.Lambda*n*(xBar-nig.Mu)*(xBar-nig.Mu)/(2*lambdaNew)
+
+	return nig.posterior(muNew, lambdaNew, alphaNew, betaNew)
+}
+
+// UpdateSingle updates with a single observation
+func (nig *NormalInverseGamma) UpdateSingle(observation float64) Posterior {
+	return nig.Update([]float64{observation})
+}
+
+// posterior returns a NormalInverseGammaPosterior that shares the receiver's random source
+func (nig *NormalInverseGamma) posterior(mu, lambda, alpha, beta float64) *NormalInverseGammaPosterior {
+	post := NewNormalInverseGamma(mu, lambda, alpha, beta)
+	post.SetSource(nig.dist.Src)
+	return &NormalInverseGammaPosterior{NormalInverseGamma: post}
+}
+
+// MeanMarginal returns the marginal Student's t distribution of the mean
+func (nig *NormalInverseGamma) MeanMarginal() *StudentT {
+	return nig.StudentT
+}
+
+// VarianceMarginal returns the marginal Inverse-Gamma distribution of the variance
+func (nig *NormalInverseGamma) VarianceMarginal() *InverseGamma {
+	marginal := NewInverseGamma(nig.Alpha, nig.Beta)
+	marginal.SetSource(nig.dist.Src)
+	return marginal
+}
+
+// NormalInverseGammaPosterior represents a Normal-Inverse-Gamma posterior. Its
+// Distribution methods and intervals refer to the marginal of the mean.
+type NormalInverseGammaPosterior struct {
+	*NormalInverseGamma
+}
+
+// CredibleInterval returns the credible interval for the mean
+func (np *NormalInverseGammaPosterior) CredibleInterval(confidence float64) (lower, upper float64) {
+	alpha := (1 - confidence) / 2
+	return np.Quantile(alpha), np.Quantile(1 - alpha)
+}
+
+// MAP returns the maximum a posteriori estimate of the mean
+func (np *NormalInverseGammaPosterior) MAP() float64 {
+	return np.Mu
+}
+
+// HPD returns the highest posterior density interval for the mean
+func (np *NormalInverseGammaPosterior) HPD(confidence float64) (lower, upper float64) {
+	// The Student's t marginal is symmetric, so HPD equals credible interval
+	return np.CredibleInterval(confidence)
+}
+
+// PosteriorPredictive returns the Student's t distribution of the sum of the
+// next n observations
+func (np *NormalInverseGammaPosterior) PosteriorPredictive(n int) Distribution {
+	nf := float64(n)
+	scale := math.Sqrt(np.Beta / np.Alpha * nf * (1 + nf/np.Lambda))
+	predictive := NewStudentT(nf*np.Mu, scale, 2*np.Alpha)
+	predictive.SetSource(np.dist.Src)
+	return predictive
+}
@@ -0,0 +1,87 @@
+package distributions
+
+import (
+	"math"
+	"math/rand/v2"
+
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// Poisson represents the number of events in a fixed interval with rate Lambda
+type Poisson struct {
+	Lambda float64
+	dist   distuv.Poisson
+}
+
+// NewPoisson creates a new Poisson distribution
+func NewPoisson(lambda float64) *Poisson {
+	return &Poisson{
+		Lambda: lambda,
+		dist:   distuv.Poisson{Lambda: lambda},
+	}
+}
+
+// PMF returns the probability mass function at k
+func (p *Poisson) PMF(k int) float64 {
+	return p.dist.Prob(float64(k))
+}
+
+// LogPMF returns the log probability mass function at k
+func (p *Poisson) LogPMF(k int) float64 {
+	return p.dist.LogProb(float64(k))
+}
+
+// PDF returns the probability mass at x, which is zero for non-integer x
+func (p *Poisson) PDF(x float64) float64 {
+	return p.dist.Prob(x)
+}
+
+// LogPDF returns the log probability mass at x
+func (p *Poisson) LogPDF(x float64) float64 {
+	return p.dist.LogProb(x)
+}
+
+// CDF returns the cumulative distribution function at x
+func (p *Poisson) CDF(x float64) float64 {
+	return p.dist.CDF(x)
+}
+
+// Quantile returns the smallest integer k with CDF(k) >= q
+func (p *Poisson) Quantile(q float64) float64 {
+	cdf := func(k int) float64 { return p.CDF(float64(k)) }
+	return discreteQuantile(cdf, q, int(math.Ceil(p.Lambda)), -1)
+}
+
+// Sample generates a random sample
+func (p *Poisson) Sample() float64 {
+	return p.dist.Rand()
+}
+
+// SetSource sets the random source used for sampling; nil uses the global source
+func (p *Poisson) SetSource(src rand.Source) {
+	p.dist.Src = src
+}
+
+// SampleN generates n random samples
+func (p *Poisson) SampleN(n int) []float64 {
+	samples := make([]float64, n)
+	for i := 0; i < n; i++ {
+		samples[i] = p.Sample()
+	}
+	return samples
+}
+
+// Mean returns the expected value
+func (p *Poisson) Mean() float64 {
+	return p.dist.Mean()
+}
+
+// Variance returns the variance
+func (p *Poisson) Variance() float64 {
+	return p.dist.Variance()
+}
+
+// StdDev returns the standard deviation
+func (p *Poisson) StdDev() float64 {
+	return p.dist.StdDev()
+}
@@ -0,0 +1,140 @@
+package distributions
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+// batches splits data into consecutive batches of uneven sizes, including an
+// empty one
+func batches(data []float64) [][]float64 {
+	sizes := []int{1, 0, 7, 30, 2}
+	var out [][]float64
+	start := 0
+	for i := 0; start < len(data); i++ {
+		end := min(start+sizes[i%len(sizes)], len(data))
+		out = append(out, data[start:end])
+		start = end
+	}
+	return out
+}
+
+// updateSequentially feeds each batch to the posterior of the previous one
+func updateSequentially(prior Prior, data []float64) Posterior {
+	var post Posterior
+	current := prior
+	for _, batch := range batches(data) {
+		post = current.Update(batch)
+		current = post.(SequentialPosterior)
+	}
+	return post
+}
+
+func closeTo(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-12*math.Max(math.Abs(a), math.Abs(b))
+}
+
+func TestSequentialBetaIsExact(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 1))
+	data := make([]float64, 500)
+	for i := range data {
+		if rng.Float64() < 0.3 {
+			data[i] = 1
+		}
+	}
+	for _, prior := range []*Beta{NewBeta(1, 1), NewBeta(0.5, 0.5), NewBeta(2.3, 17.1)} {
+		once := prior.Update(data).(*BetaPosterior)
+		seq := updateSequentially(prior, data).(*BetaPosterior)
+		if once.Alpha != seq.Alpha || once.Beta.Beta != seq.Beta.Beta {
+			t.Errorf("Beta(%v, %v): once Beta(%v, %v), sequential Beta(%v, %v)",
+				prior.Alpha, prior.Beta, once.Alpha, once.Beta.Beta, seq.Alpha, seq.Beta.Beta)
+		}
+	}
+}
+
+func TestSequentialGammaIsExact(t *testing.T) {
+	rng := rand.New(rand.NewPCG(2, 2))
+	data := make([]float64, 500)
+	for i := range data {
+		data[i] = float64(rng.IntN(20))
+	}
+	for _, prior := range []*Gamma{NewGamma(1, 1), NewGamma(0.5, 0.25), NewGamma(2, 0.1)} {
+		once := prior.Update(data).(*GammaPosterior)
+		seq := updateSequentially(prior, data).(*GammaPosterior)
+		if once.Alpha != seq.Alpha || once.Beta != seq.Beta {
+			t.Errorf("Gamma(%v, %v): once Gamma(%v, %v), sequential Gamma(%v, %v)",
+				prior.Alpha, prior.Beta, once.Alpha, once.Beta, seq.Alpha, seq.Beta)
+		}
+	}
+}
+
+func TestSequentialDirichletIsExact(t *testing.T) {
+	rng := rand.New(rand.NewPCG(3, 3))
+	prior := NewDirichlet([]float64{1, 0.5, 2.5})
+
+	total := make([]float64, 3)
+	var current MultivariatePrior = prior
+	var seq *DirichletPosterior
+	for range 40 {
+		counts := make([]float64, 3)
+		for range rng.IntN(15) {
+			counts[rng.IntN(3)]++
+		}
+		for i, c := range counts {
+			total[i] += c
+		}
+		seq = current.Update(counts).(*DirichletPosterior)
+		current = seq
+	}
+
+	once := prior.Update(total).(*DirichletPosterior)
+	for i := range total {
+		if once.Alpha[i] != seq.Alpha[i] {
+			t.Errorf("alpha[%d]: once %v, sequential %v", i, once.Alpha[i], seq.Alpha[i])
+		}
+	}
+}
+
+func TestSequentialNormalAgreesToRounding(t *testing.T) {
+	rng := rand.New(rand.NewPCG(4, 4))
+	data := make([]float64, 500)
+	for i := range data {
+		data[i] = 3.7 + 1.3*rng.NormFloat64()
+	}
+	prior := NewNormalConjugate(0, 10, 1.69)
+
+	once := prior.Update(data).(*NormalPosterior)
+	seq := updateSequentially(prior, data).(*NormalPosterior)
+	if !closeTo(once.Mu, seq.Mu) || !closeTo(once.Sigma, seq.Sigma) {
+		t.Errorf("once Normal(%v, %v), sequential Normal(%v, %v)", once.Mu, once.Sigma, seq.Mu, seq.Sigma)
+	}
+	if seq.KnownVariance != prior.KnownVariance {
+		t.Errorf("KnownVariance = %v, want %v", seq.KnownVariance, prior.KnownVariance)
+	}
+}
+
+func TestSequentialNormalInverseGammaAgreesToRounding(t *testing.T) {
+	rng := rand.New(rand.NewPCG(5, 5))
+	data := make([]float64, 500)
+	for i := range data {
+		data[i] = -2 + 0.8*rng.NormFloat64()
+	}
+	prior := NewNormalInverseGamma(0, 1, 2, 3)
+
+	once := prior.Update(data).(*NormalInverseGammaPosterior)
+	seq := updateSequentially(prior, data).(*NormalInverseGammaPosterior)
+	for _, p := range []struct {
+		name       string
+		once, seqv float64
+	}{
+		{"Mu", once.Mu, seq.Mu},
+		{"Lambda", once.Lambda, seq.Lambda},
+		{"Alpha", once.Alpha, seq.Alpha},
+		{"Beta", once.Beta, seq.Beta},
+	} {
+		if !closeTo(p.once, p.seqv) {
+			t.Errorf("%s: once %v, sequential %v", p.name, p.once, p.seqv)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package distributions
+
+// SufficientStatistics summarizes observations so that conjugate priors can be
+// updated without retaining the raw data. The spread is kept as a centred
+// second moment so that it stays accurate when the mean is large relative to
+// the variance.
+type SufficientStatistics struct {
+	N   float64 // number of observations
+	Sum float64 // sum of observations, or successes for binary data
+	M2  float64 // sum of squared deviations from the sample mean
+}
+
+// BinomialStatistics summarizes successes out of trials binary observations
+func BinomialStatistics(successes, trials int) SufficientStatistics {
+	stats := SufficientStatistics{N: float64(trials), Sum: float64(successes)}
+	if trials > 0 {
+		stats.M2 = float64(successes) * float64(trials-successes) / float64(trials)
+	}
+	return stats
+}
+
+// ComputeStatistics summarizes raw observations using Welford's algorithm
+func ComputeStatistics(data []float64) SufficientStatistics {
+	var stats SufficientStatistics
+	mean := 0.0
+	for _, x := range data {
+		stats.N++
+		stats.Sum += x
+		delta := x - mean
+		mean += delta / stats.N
+		stats.M2 += delta * (x - mean)
+	}
+	return stats
+}
+
+// Add returns the statistics of the union of both sets of observations,
+// combining the second moments with Chan et al.'s pairwise update
+func (s SufficientStatistics) Add(other SufficientStatistics) SufficientStatistics {
+	n := s.N + other.N
+	if s.N == 0 || other.N == 0 {
+		return SufficientStatistics{N: n, Sum: s.Sum + other.Sum, M2: s.M2 + other.M2}
+	}
+	delta := other.Mean() - s.Mean()
+	return SufficientStatistics{
+		N:   n,
+		Sum: s.Sum + other.Sum,
+		M2:  s.M2 + other.M2 + delta*delta*s.N*other.N/n,
+	}
+}
+
+// Mean returns the sample mean
+func (s SufficientStatistics) Mean() float64 {
+	return s.Sum / s.N
+}
+
+// SumSquaredDeviations returns the sum of squared deviations from the sample mean
+func (s SufficientStatistics) SumSquaredDeviations() float64 {
+	if s.N == 0 || s.M2 < 0 {
+		return 0
+	}
+	return s.M2
+}
+
+// StatisticsUpdater is implemented by priors whose update depends on the data
+// only through sufficient statistics
+type StatisticsUpdater interface {
+	// Statistics summarizes raw observations in the form UpdateStatistics expects
+	Statistics(data []float64) SufficientStatistics
+
+	// UpdateStatistics returns the posterior given summarized observations
+	UpdateStatistics(stats SufficientStatistics) Posterior
+}
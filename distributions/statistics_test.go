@@ -0,0 +1,78 @@
+package distributions
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+// twoPass returns the sum of squared deviations computed from the mean
+func twoPass(data []float64) float64 {
+	mean := 0.0
+	for _, x := range data {
+		mean += x
+	}
+	mean /= float64(len(data))
+	ss := 0.0
+	for _, x := range data {
+		ss += (x - mean) * (x - mean)
+	}
+	return ss
+}
+
+func TestStatisticsPrecisionWithLargeMean(t *testing.T) {
+	// Values near 1e9 with unit spread: SumSq - Sum²/N cancels every
+	// significant digit of the spread
+	rng := rand.New(rand.NewPCG(1, 1))
+	data := make([]float64, 10000)
+	for i := range data {
+		data[i] = 1e9 + rng.NormFloat64()
+	}
+	want := twoPass(data)
+
+	whole := ComputeStatistics(data)
+	merged := ComputeStatistics(data[:3000]).Add(ComputeStatistics(data[3000:7001])).Add(ComputeStatistics(data[7001:]))
+	for _, tt := range []struct {
+		name  string
+		stats SufficientStatistics
+	}{
+		{"computed", whole},
+		{"merged", merged},
+	} {
+		if got := tt.stats.SumSquaredDeviations(); math.Abs(got-want) > 1e-6*want {
+			t.Errorf("%s: sum of squared deviations = %v, want %v", tt.name, got, want)
+		}
+		if tt.stats.N != 10000 || math.Abs(tt.stats.Mean()-1e9) > 0.1 {
+			t.Errorf("%s: N = %v, mean = %v", tt.name, tt.stats.N, tt.stats.Mean())
+		}
+	}
+
+	post := NewNormalInverseGamma(1e9, 1e-6, 1e-3, 1e-3).UpdateStatistics(whole).(*NormalInverseGammaPosterior)
+	if variance := post.VarianceMarginal().Mean(); math.Abs(variance-1) > 0.05 {
+		t.Errorf("posterior mean of the variance = %v, want about 1", variance)
+	}
+}
+
+func TestStatisticsAdd(t *testing.T) {
+	data := []float64{2, 4, 4, 4, 5, 5, 7, 9}
+	for split := range len(data) + 1 {
+		stats := ComputeStatistics(data[:split]).Add(ComputeStatistics(data[split:]))
+		if stats.N != 8 || stats.Sum != 40 || math.Abs(stats.SumSquaredDeviations()-32) > 1e-12 {
+			t.Errorf("split at %d: statistics %+v, want N 8, Sum 40, M2 32", split, stats)
+		}
+	}
+	if empty := ComputeStatistics(nil); empty.SumSquaredDeviations() != 0 {
+		t.Errorf("empty statistics %+v", empty)
+	}
+}
+
+func TestBinomialStatisticsMatchData(t *testing.T) {
+	data := []float64{1, 0, 0, 1, 1, 0, 0, 0, 0, 0}
+	got, want := BinomialStatistics(3, 10), ComputeStatistics(data)
+	if got.N != want.N || got.Sum != want.Sum || math.Abs(got.M2-want.M2) > 1e-12 {
+		t.Errorf("BinomialStatistics(3, 10) = %+v, want %+v", got, want)
+	}
+	if zero := BinomialStatistics(0, 0); zero.SumSquaredDeviations() != 0 {
+		t.Errorf("BinomialStatistics(0, 0) = %+v", zero)
+	}
+}
@@ -0,0 +1,98 @@
+package distributions
+
+import (
+	"math"
+	"math/rand/v2"
+
+	"gonum.org/v1/gonum/mathext"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// StudentT represents a location-scale Student's t distribution
+type StudentT struct {
+	Mu    float64 // location
+	Sigma float64 // scale
+	Nu    float64 // degrees of freedom
+	dist  distuv.StudentsT
+}
+
+// NewStudentT creates a new StudentT distribution
+func NewStudentT(mu, sigma, nu float64) *StudentT {
+	return &StudentT{
+		Mu:    mu,
+		Sigma: sigma,
+		Nu:    nu,
+		dist:  distuv.StudentsT{Mu: mu, Sigma: sigma, Nu: nu},
+	}
+}
+
+// PDF returns the probability density function at x
+func (t *StudentT) PDF(x float64) float64 {
+	return t.dist.Prob(x)
+}
+
+// LogPDF returns the log probability density function at x
+func (t *StudentT) LogPDF(x float64) float64 {
+	return t.dist.LogProb(x)
+}
+
+// CDF returns the cumulative distribution function at x
+func (t *StudentT) CDF(x float64) float64 {
+	return t.dist.CDF(x)
+}
+
+// Quantile returns the inverse CDF at probability p
+func (t *StudentT) Quantile(p float64) float64 {
+	return t.dist.Quantile(p)
+}
+
+// Sample generates a random sample
+func (t *StudentT) Sample() float64 {
+	return t.dist.Rand()
+}
+
+// SetSource sets the random source used for sampling; nil uses the global source
+func (t *StudentT) SetSource(src rand.Source) {
+	t.dist.Src = src
+}
+
+// SampleN generates n random samples
+func (t *StudentT) SampleN(n int) []float64 {
+	samples := make([]float64, n)
+	for i := 0; i < n; i++ {
+		samples[i] = t.Sample()
+	}
+	return samples
+}
+
+// Mean returns the expected value
+func (t *StudentT) Mean() float64 {
+	return t.dist.Mean()
+}
+
+// Variance returns the variance
+func (t *StudentT) Variance() float64 {
+	return t.dist.Variance()
+}
+
+// StdDev returns the standard deviation
+func (t *StudentT) StdDev() float64 {
+	return t.dist.StdDev()
+}
+
+// Mode returns the mode
+func (t *StudentT) Mode() []float64 {
+	return []float64{t.Mu}
+}
+
+// Median returns the median
+func (t *StudentT) Median() float64 {
+	return t.Mu
+}
+
+// Entropy returns the differential entropy
+func (t *StudentT) Entropy() float64 {
+	half := (t.Nu + 1) / 2
+	return half*(mathext.Digamma(half)-mathext.Digamma(t.Nu/2)) +
+		0.5*math.Log(t.Nu) + logBeta(t.Nu/2, 0.5) + math.Log(t.Sigma)
+}
@@ -0,0 +1,418 @@
+package inference
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"gonum.org/v1/gonum/dsp/fourier"
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// The convergence diagnostics below follow Vehtari, Gelman, Simpson,
+// Carpenter and Bürkner (2021), "Rank-normalization, folding, and
+// localization: an improved R-hat for assessing convergence of MCMC". They
+// take the draws of one parameter as [][]float64, one slice per chain, which
+// is what Trace.Chains returns. Chains of different lengths are truncated to
+// the shortest, and diagnostics are NaN when fewer than minDraws remain.
+
+// minDraws is the fewest draws per chain the diagnostics accept, so that each
+// half of a split chain holds at least two
+const minDraws = 4
+
+// Autocorrelation returns the autocorrelation of a chain at lags 0 through
+// maxLag, computed with the fast Fourier transform
+func Autocorrelation(chain []float64, maxLag int) []float64 {
+	acov := autocovariance(chain)
+	maxLag = min(maxLag, len(chain)-1)
+	if maxLag < 0 || acov[0] == 0 {
+		return nil
+	}
+	acf := make([]float64, maxLag+1)
+	for t := range acf {
+		acf[t] = acov[t] / acov[0]
+	}
+	return acf
+}
+
+// SplitRHat returns the potential scale reduction factor of the chains after
+// splitting each in half; values near 1 indicate the chains agree
+func SplitRHat(chains [][]float64) float64 {
+	if commonLength(chains) < minDraws {
+		return math.NaN()
+	}
+	return rHat(splitChains(chains))
+}
+
+// RHat returns the rank-normalized split R-hat: the larger of the split R-hat
+// of the rank-normalized draws, which checks location, and of the
+// rank-normalized folded draws |x - median|, which checks scale. Values above
+// 1.01 suggest the chains have not converged.
+func RHat(chains [][]float64) float64 {
+	if commonLength(chains) < minDraws {
+		return math.NaN()
+	}
+	split := splitChains(chains)
+	return math.Max(rHat(rankNormalize(split)), rHat(rankNormalize(fold(split))))
+}
+
+// ESS returns the effective sample size of the mean of the split chains
+func ESS(chains [][]float64) float64 {
+	if commonLength(chains) < minDraws {
+		return math.NaN()
+	}
+	return effectiveSampleSize(splitChains(chains))
+}
+
+// BulkESS returns the effective sample size of the rank-normalized split
+// chains, a measure of how well the centre of the distribution is explored
+func BulkESS(chains [][]float64) float64 {
+	if commonLength(chains) < minDraws {
+		return math.NaN()
+	}
+	return effectiveSampleSize(rankNormalize(splitChains(chains)))
+}
+
+// TailESS returns the smaller effective sample size of the indicators of
+// being below the 5% and above the 95% quantiles, a measure of how well the
+// tails of the distribution are explored
+func TailESS(chains [][]float64) float64 {
+	if commonLength(chains) < minDraws {
+		return math.NaN()
+	}
+	split := splitChains(chains)
+	pooled := pool(split)
+	slices.Sort(pooled)
+	lower := stat.Quantile(0.05, stat.Empirical, pooled, nil)
+	upper := stat.Quantile(0.95, stat.Empirical, pooled, nil)
+	return math.Min(
+		effectiveSampleSize(indicator(split, func(x float64) bool { return x <= lower })),
+		effectiveSampleSize(indicator(split, func(x float64) bool { return x <= upper })),
+	)
+}
+
+// MCSE returns the Monte Carlo standard error of the posterior mean estimate
+func MCSE(chains [][]float64) float64 {
+	if commonLength(chains) < minDraws {
+		return math.NaN()
+	}
+	return stat.StdDev(pool(splitChains(chains)), nil) / math.Sqrt(ESS(chains))
+}
+
+// ParameterDiagnostics summarizes the draws and convergence of one parameter
+type ParameterDiagnostics struct {
+	Name    string
+	Mean    float64
+	StdDev  float64
+	MCSE    float64
+	Q5      float64
+	Median  float64
+	Q95     float64
+	BulkESS float64
+	TailESS float64
+	RHat    float64
+}
+
+// ChainReport is a convergence report for every parameter of a trace
+type ChainReport struct {
+	Parameters  []ParameterDiagnostics
+	Chains      int
+	Draws       int // draws per chain
+	Divergences int // divergent transitions summed over chains
+}
+
+// Diagnose computes summaries and convergence diagnostics for every parameter
+// of a trace. Parameters are named by names where given and by index otherwise.
+// Statistics that cannot be computed, e.g. for a trace with no draws, are NaN.
+func Diagnose(t *Trace, names ...string) ChainReport {
+	report := ChainReport{
+		Parameters: make([]ParameterDiagnostics, t.Dim()),
+		Chains:     t.NumChains(),
+	}
+	if t.NumChains() > 0 && t.Dim() > 0 {
+		report.Draws = len(t.Values[0][0])
+	}
+	for _, d := range t.Divergences {
+		report.Divergences += d
+	}
+
+	nan := math.NaN()
+	for p := range report.Parameters {
+		chains := t.Chains(p)
+		name := fmt.Sprintf("param[%d]", p)
+		if p < len(names) {
+			name = names[p]
+		}
+		report.Parameters[p] = ParameterDiagnostics{
+			Name:    name,
+			Mean:    nan,
+			StdDev:  nan,
+			MCSE:    MCSE(chains),
+			Q5:      nan,
+			Median:  nan,
+			Q95:     nan,
+			BulkESS: BulkESS(chains),
+			TailESS: TailESS(chains),
+			RHat:    RHat(chains),
+		}
+
+		sorted := pool(chains)
+		if len(sorted) == 0 {
+			continue
+		}
+		slices.Sort(sorted)
+		diag := &report.Parameters[p]
+		diag.Mean = stat.Mean(sorted, nil)
+		diag.StdDev = stat.StdDev(sorted, nil)
+		diag.Q5 = stat.Quantile(0.05, stat.Empirical, sorted, nil)
+		diag.Median = stat.Quantile(0.5, stat.Empirical, sorted, nil)
+		diag.Q95 = stat.Quantile(0.95, stat.Empirical, sorted, nil)
+	}
+	return report
+}
+
+// Problems lists every parameter whose R-hat exceeds maxRHat or whose bulk or
+// tail effective sample size is below minESS, and any divergent transitions.
+// An empty list means the run passed the checks.
+func (r ChainReport) Problems(maxRHat, minESS float64) []string {
+	var problems []string
+	if r.Divergences > 0 {
+		problems = append(problems, fmt.Sprintf("%d divergent transitions", r.Divergences))
+	}
+	for _, p := range r.Parameters {
+		if !(p.RHat <= maxRHat) {
+			problems = append(problems, fmt.Sprintf("%s: R-hat %.3f exceeds %.3f", p.Name, p.RHat, maxRHat))
+		}
+		if !(p.BulkESS >= minESS) {
+			problems = append(problems, fmt.Sprintf("%s: bulk ESS %.0f below %.0f", p.Name, p.BulkESS, minESS))
+		}
+		if !(p.TailESS >= minESS) {
+			problems = append(problems, fmt.Sprintf("%s: tail ESS %.0f below %.0f", p.Name, p.TailESS, minESS))
+		}
+	}
+	return problems
+}
+
+// Converged reports whether the run passes the recommended checks: no
+// divergences, R-hat at most 1.01 and bulk and tail ESS of at least 100 per
+// chain
+func (r ChainReport) Converged() bool {
+	return len(r.Problems(1.01, float64(100*r.Chains))) == 0
+}
+
+// String renders the report as a table
+func (r ChainReport) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d chains, %d draws each, %d divergences\n\n", r.Chains, r.Draws, r.Divergences)
+	fmt.Fprintf(&b, "%-12s %10s %10s %10s %10s %10s %10s %8s %8s %6s\n",
+		"Parameter", "Mean", "SD", "MCSE", "5%", "50%", "95%", "ESSbulk", "ESStail", "R-hat")
+	for _, p := range r.Parameters {
+		fmt.Fprintf(&b, "%-12s %10.4g %10.4g %10.2g %10.4g %10.4g %10.4g %8.0f %8.0f %6.3f\n",
+			p.Name, p.Mean, p.StdDev, p.MCSE, p.Q5, p.Median, p.Q95, p.BulkESS, p.TailESS, p.RHat)
+	}
+	return b.String()
+}
+
+// autocovariance returns the biased autocovariance of x at every lag
+func autocovariance(x []float64) []float64 {
+	n := len(x)
+	if n == 0 {
+		return []float64{0}
+	}
+	mean := stat.Mean(x, nil)
+
+	// Zero-padding to twice the length avoids circular wrap-around
+	padded := make([]float64, 2*n)
+	for i, v := range x {
+		padded[i] = v - mean
+	}
+	fft := fourier.NewFFT(len(padded))
+	coeffs := fft.Coefficients(nil, padded)
+	for i, c := range coeffs {
+		coeffs[i] = complex(real(c)*real(c)+imag(c)*imag(c), 0)
+	}
+	seq := fft.Sequence(nil, coeffs)
+
+	acov := make([]float64, n)
+	for t := range acov {
+		acov[t] = seq[t] / float64(len(padded)) / float64(n)
+	}
+	return acov
+}
+
+// effectiveSampleSize estimates the effective sample size of chains using
+// Geyer's initial monotone sequence of summed autocorrelation pairs
+func effectiveSampleSize(chains [][]float64) float64 {
+	m := len(chains)
+	if m == 0 {
+		return math.NaN()
+	}
+	n := len(chains[0])
+	if n < 4 {
+		return math.NaN()
+	}
+
+	acovs := make([][]float64, m)
+	means := make([]float64, m)
+	w := 0.0
+	for c, chain := range chains {
+		acovs[c] = autocovariance(chain)
+		means[c] = stat.Mean(chain, nil)
+		w += acovs[c][0] * float64(n) / float64(n-1)
+	}
+	w /= float64(m)
+	varPlus := w * float64(n-1) / float64(n)
+	if m > 1 {
+		varPlus += stat.Variance(means, nil)
+	}
+	if varPlus == 0 || math.IsNaN(varPlus) {
+		return math.NaN()
+	}
+
+	rho := func(t int) float64 {
+		meanAcov := 0.0
+		for _, acov := range acovs {
+			meanAcov += acov[t]
+		}
+		meanAcov /= float64(m)
+		return 1 - (w-meanAcov)/varPlus
+	}
+
+	// Sum autocorrelation pairs while they stay positive, forcing the pair
+	// sums to be non-increasing
+	tau := -1.0
+	prevPair := math.Inf(1)
+	for t := 0; t+1 < n; t += 2 {
+		pair := rho(t) + rho(t+1)
+		if pair <= 0 {
+			break
+		}
+		pair = math.Min(pair, prevPair)
+		tau += 2 * pair
+		prevPair = pair
+	}
+
+	total := float64(m * n)
+	return math.Min(total/tau, total*math.Log10(total))
+}
+
+// rHat returns the potential scale reduction factor of the chains
+func rHat(chains [][]float64) float64 {
+	m := len(chains)
+	if m < 2 || len(chains[0]) < 2 {
+		return math.NaN()
+	}
+	n := float64(len(chains[0]))
+
+	means := make([]float64, m)
+	w := 0.0
+	for c, chain := range chains {
+		means[c] = stat.Mean(chain, nil)
+		w += stat.Variance(chain, nil)
+	}
+	w /= float64(m)
+	b := n * stat.Variance(means, nil)
+	return math.Sqrt(((n-1)/n*w + b/n) / w)
+}
+
+// commonLength returns the length of the shortest chain, or zero if there
+// are no chains
+func commonLength(chains [][]float64) int {
+	if len(chains) == 0 {
+		return 0
+	}
+	n := len(chains[0])
+	for _, chain := range chains {
+		n = min(n, len(chain))
+	}
+	return n
+}
+
+// splitChains truncates the chains to a common length and splits each in half
+func splitChains(chains [][]float64) [][]float64 {
+	n := commonLength(chains)
+	half := n / 2
+
+	split := make([][]float64, 0, 2*len(chains))
+	for _, chain := range chains {
+		split = append(split, chain[:half], chain[n-half:n])
+	}
+	return split
+}
+
+// rankNormalize replaces each draw by the normal score of its rank among all
+// draws, with ties given their average rank
+func rankNormalize(chains [][]float64) [][]float64 {
+	type draw struct {
+		value        float64
+		chain, index int
+	}
+	var draws []draw
+	for c, chain := range chains {
+		for i, v := range chain {
+			draws = append(draws, draw{v, c, i})
+		}
+	}
+	slices.SortFunc(draws, func(a, b draw) int { return cmp.Compare(a.value, b.value) })
+
+	out := make([][]float64, len(chains))
+	for c, chain := range chains {
+		out[c] = make([]float64, len(chain))
+	}
+	total := float64(len(draws))
+	for start := 0; start < len(draws); {
+		end := start + 1
+		for end < len(draws) && draws[end].value == draws[start].value {
+			end++
+		}
+		rank := float64(start+end+1) / 2
+		z := distuv.UnitNormal.Quantile((rank - 0.375) / (total + 0.25))
+		for _, d := range draws[start:end] {
+			out[d.chain][d.index] = z
+		}
+		start = end
+	}
+	return out
+}
+
+// fold returns the absolute deviations of the draws from their pooled median
+func fold(chains [][]float64) [][]float64 {
+	sorted := pool(chains)
+	slices.Sort(sorted)
+	median := stat.Quantile(0.5, stat.Empirical, sorted, nil)
+
+	out := make([][]float64, len(chains))
+	for c, chain := range chains {
+		out[c] = make([]float64, len(chain))
+		for i, v := range chain {
+			out[c][i] = math.Abs(v - median)
+		}
+	}
+	return out
+}
+
+// indicator maps each draw to 1 if it satisfies pred and 0 otherwise
+func indicator(chains [][]float64, pred func(float64) bool) [][]float64 {
+	out := make([][]float64, len(chains))
+	for c, chain := range chains {
+		out[c] = make([]float64, len(chain))
+		for i, v := range chain {
+			if pred(v) {
+				out[c][i] = 1
+			}
+		}
+	}
+	return out
+}
+
+// pool concatenates the chains into a new slice
+func pool(chains [][]float64) []float64 {
+	var pooled []float64
+	for _, chain := range chains {
+		pooled = append(pooled, chain...)
+	}
+	return pooled
+}
@@ -0,0 +1,188 @@
+package inference
+
+import (
+	"math"
+	"math/rand/v2"
+	"strings"
+	"testing"
+)
+
+// ar1Chains returns chains of an AR(1) process x[t] = phi*x[t-1] + e[t]
+// shifted by offset(c) for chain c, started from its stationary distribution
+func ar1Chains(seed uint64, chains, n int, phi float64, offset func(c int) float64) [][]float64 {
+	rng := rand.New(rand.NewPCG(seed, 1))
+	out := make([][]float64, chains)
+	for c := range out {
+		x := rng.NormFloat64() / math.Sqrt(1-phi*phi)
+		out[c] = make([]float64, n)
+		for i := range out[c] {
+			x = phi*x + rng.NormFloat64()
+			out[c][i] = x + offset(c)
+		}
+	}
+	return out
+}
+
+func noOffset(int) float64 { return 0 }
+
+func TestIIDChains(t *testing.T) {
+	chains := ar1Chains(1, 4, 1000, 0, noOffset)
+	total := 4000.0
+
+	if r := RHat(chains); math.Abs(r-1) > 0.01 {
+		t.Errorf("RHat = %v, want about 1", r)
+	}
+	if r := SplitRHat(chains); math.Abs(r-1) > 0.01 {
+		t.Errorf("SplitRHat = %v, want about 1", r)
+	}
+	for name, ess := range map[string]func([][]float64) float64{
+		"ESS": ESS, "BulkESS": BulkESS, "TailESS": TailESS,
+	} {
+		if got := ess(chains); math.Abs(got/total-1) > 0.15 {
+			t.Errorf("%s = %.0f, want about %.0f", name, got, total)
+		}
+	}
+	if got, want := MCSE(chains), 1/math.Sqrt(total); math.Abs(got/want-1) > 0.15 {
+		t.Errorf("MCSE = %v, want about %v", got, want)
+	}
+}
+
+func TestShiftedChains(t *testing.T) {
+	chains := ar1Chains(2, 4, 1000, 0, func(c int) float64 { return float64(c) })
+	if r := RHat(chains); r < 1.1 {
+		t.Errorf("RHat = %v, want well above 1.01", r)
+	}
+	if r := SplitRHat(chains); r < 1.1 {
+		t.Errorf("SplitRHat = %v, want well above 1.01", r)
+	}
+}
+
+func TestScaleMismatchDetectedByFolding(t *testing.T) {
+	chains := ar1Chains(3, 4, 1000, 0, noOffset)
+	for i := range chains[0] {
+		chains[0][i] *= 4
+	}
+	if r := RHat(chains); r < 1.05 {
+		t.Errorf("RHat = %v, want above 1.05 for chains with different scales", r)
+	}
+}
+
+func TestAR1EffectiveSampleSize(t *testing.T) {
+	const phi = 0.5
+	chains := ar1Chains(4, 4, 5000, phi, noOffset)
+	want := 20000 * (1 - phi) / (1 + phi)
+
+	if got := ESS(chains); math.Abs(got/want-1) > 0.15 {
+		t.Errorf("ESS = %.0f, want about %.0f", got, want)
+	}
+	if got := BulkESS(chains); math.Abs(got/want-1) > 0.15 {
+		t.Errorf("BulkESS = %.0f, want about %.0f", got, want)
+	}
+
+	acf := Autocorrelation(chains[0], 3)
+	for lag, got := range acf {
+		if want := math.Pow(phi, float64(lag)); math.Abs(got-want) > 0.05 {
+			t.Errorf("Autocorrelation at lag %d = %.3f, want %.3f", lag, got, want)
+		}
+	}
+}
+
+func TestAutocorrelationEdgeCases(t *testing.T) {
+	if acf := Autocorrelation(nil, 5); acf != nil {
+		t.Errorf("Autocorrelation(nil) = %v, want nil", acf)
+	}
+	if acf := Autocorrelation([]float64{2, 2, 2}, 2); acf != nil {
+		t.Errorf("Autocorrelation(constant) = %v, want nil", acf)
+	}
+	if acf := Autocorrelation([]float64{1, 2, 3}, 10); len(acf) != 3 {
+		t.Errorf("len(Autocorrelation) = %d, want lags capped at 3", len(acf))
+	}
+}
+
+func TestShortAndEmptyChains(t *testing.T) {
+	full := []float64{1, 2, 3, 4, 5, 6}
+	cases := map[string][][]float64{
+		"no chains":   nil,
+		"empty chain": {full, full, full, {}},
+		"too short":   {full, {1, 2, 3}},
+	}
+	for name, chains := range cases {
+		for fn, diag := range map[string]func([][]float64) float64{
+			"RHat": RHat, "SplitRHat": SplitRHat, "ESS": ESS,
+			"BulkESS": BulkESS, "TailESS": TailESS, "MCSE": MCSE,
+		} {
+			if got := diag(chains); !math.IsNaN(got) {
+				t.Errorf("%s: %s = %v, want NaN", name, fn, got)
+			}
+		}
+	}
+}
+
+func TestConstantChains(t *testing.T) {
+	chains := [][]float64{{3, 3, 3, 3, 3, 3}, {3, 3, 3, 3, 3, 3}}
+	for fn, diag := range map[string]func([][]float64) float64{
+		"RHat": RHat, "ESS": ESS, "BulkESS": BulkESS, "TailESS": TailESS, "MCSE": MCSE,
+	} {
+		if got := diag(chains); !math.IsNaN(got) {
+			t.Errorf("%s = %v, want NaN", fn, got)
+		}
+	}
+}
+
+func TestDiagnose(t *testing.T) {
+	trace := &Trace{
+		Values:      [][][]float64{ar1Chains(5, 4, 1000, 0, noOffset), ar1Chains(6, 4, 1000, 0, func(c int) float64 { return float64(c) })},
+		Divergences: []int{0, 1, 0, 0},
+	}
+	report := Diagnose(trace, "good")
+
+	if report.Chains != 4 || report.Draws != 1000 || report.Divergences != 1 {
+		t.Fatalf("report = %d chains, %d draws, %d divergences; want 4, 1000, 1",
+			report.Chains, report.Draws, report.Divergences)
+	}
+	if name := report.Parameters[1].Name; name != "param[1]" {
+		t.Errorf("unnamed parameter = %q, want param[1]", name)
+	}
+	good := report.Parameters[0]
+	if math.Abs(good.Mean) > 0.1 || math.Abs(good.StdDev-1) > 0.1 || math.Abs(good.Median) > 0.1 {
+		t.Errorf("summary = %+v, want standard normal moments", good)
+	}
+	if math.Abs(good.Q5+1.645) > 0.15 || math.Abs(good.Q95-1.645) > 0.15 {
+		t.Errorf("quantiles = [%v, %v], want about ±1.645", good.Q5, good.Q95)
+	}
+
+	problems := report.Problems(1.01, 400)
+	if len(problems) < 2 || !strings.Contains(problems[0], "divergent") || !strings.Contains(problems[1], "param[1]: R-hat") {
+		t.Errorf("Problems = %q, want the divergence and param[1]'s R-hat", problems)
+	}
+	for _, p := range problems {
+		if strings.HasPrefix(p, "good") {
+			t.Errorf("Problems flags the converged parameter: %q", p)
+		}
+	}
+	if report.Converged() {
+		t.Error("Converged() = true, want false")
+	}
+	if !strings.Contains(report.String(), "good") {
+		t.Errorf("String() does not list the parameters:\n%s", report)
+	}
+
+	trace.Values = trace.Values[:1]
+	trace.Divergences = nil
+	if report := Diagnose(trace); !report.Converged() {
+		t.Errorf("Converged() = false for iid chains: %q", report.Problems(1.01, 400))
+	}
+}
+
+func TestDiagnoseWithoutDraws(t *testing.T) {
+	trace := newTrace(2, 4, 0)
+	report := Diagnose(trace)
+	for _, p := range report.Parameters {
+		if !math.IsNaN(p.Mean) || !math.IsNaN(p.Median) || !math.IsNaN(p.RHat) {
+			t.Errorf("%s = %+v, want NaN statistics", p.Name, p)
+		}
+	}
+	if report.Converged() {
+		t.Error("Converged() = true for a trace without draws")
+	}
+}
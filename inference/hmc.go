@@ -0,0 +1,411 @@
+package inference
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+)
+
+// maxEnergyError is the growth in the Hamiltonian beyond which a trajectory
+// is reported as divergent
+const maxEnergyError = 1000
+
+// DifferentiableDensity is a log density that can also report its gradient,
+// as required by gradient-based samplers
+type DifferentiableDensity interface {
+	// LogDensityGradient returns the log density at x and stores its gradient in grad
+	LogDensityGradient(x, grad []float64) float64
+}
+
+// GradientFunc adapts a function to the DifferentiableDensity interface
+type GradientFunc func(x, grad []float64) float64
+
+// LogDensityGradient calls f(x, grad)
+func (f GradientFunc) LogDensityGradient(x, grad []float64) float64 {
+	return f(x, grad)
+}
+
+// HMC is a Hamiltonian Monte Carlo sampler with a fixed number of leapfrog
+// steps per iteration. During warmup the step size is tuned by dual averaging
+// towards TargetAcceptance and, if AdaptMassMatrix is set, a diagonal mass
+// matrix is estimated from the warmup draws.
+type HMC struct {
+	Density DifferentiableDensity
+
+	Chains  int // number of independent chains
+	Samples int // draws kept per chain after warmup
+	Warmup  int // adaptation iterations discarded at the start of each chain
+
+	// Steps is the number of leapfrog steps per iteration
+	Steps int
+	// Jitter varies each iteration's step size uniformly within this fraction
+	// of the adapted value, so that trajectories of fixed length cannot keep
+	// returning to the same point on near-periodic targets
+	Jitter float64
+	// StepSize is the initial leapfrog step size; zero picks one heuristically
+	StepSize float64
+	// TargetAcceptance is the mean acceptance probability adaptation aims for
+	TargetAcceptance float64
+	// AdaptMassMatrix enables diagonal mass matrix adaptation during warmup
+	AdaptMassMatrix bool
+
+	// Src, when set, makes the chains reproducible
+	Src rand.Source
+}
+
+// NewHMC creates a sampler with 4 chains of 1000 draws after 1000 warmup
+// iterations, 20 leapfrog steps with 20% step size jitter and a 65% target
+// acceptance rate
+func NewHMC(density DifferentiableDensity) *HMC {
+	return &HMC{
+		Density:          density,
+		Chains:           4,
+		Samples:          1000,
+		Warmup:           1000,
+		Steps:            20,
+		Jitter:           0.2,
+		TargetAcceptance: 0.65,
+		AdaptMassMatrix:  true,
+	}
+}
+
+// SetSource sets the random source used to seed the chains
+func (h *HMC) SetSource(src rand.Source) {
+	h.Src = src
+}
+
+// Sample runs the chains from jittered copies of initial. It returns an error
+// if the log density or its gradient is not finite at initial.
+func (h *HMC) Sample(initial []float64) (*Trace, error) {
+	settings := adaptiveSettings{
+		density:          h.Density,
+		chains:           h.Chains,
+		samples:          h.Samples,
+		warmup:           h.Warmup,
+		stepSize:         h.StepSize,
+		targetAcceptance: h.TargetAcceptance,
+		adaptMassMatrix:  h.AdaptMassMatrix,
+		src:              h.Src,
+	}
+	steps := max(h.Steps, 1)
+	jitter := math.Min(math.Max(h.Jitter, 0), 1)
+	return settings.run(initial, func(s *phaseState, eps float64, invMass []float64, rng *rand.Rand) (float64, bool) {
+		if jitter > 0 {
+			eps *= 1 + jitter*(2*rng.Float64()-1)
+		}
+		return hmcTransition(h.Density, s, eps, steps, invMass, rng)
+	})
+}
+
+// hmcTransition performs one HMC iteration, returning the acceptance
+// probability and whether the trajectory diverged
+func hmcTransition(density DifferentiableDensity, s *phaseState, eps float64, steps int, invMass []float64, rng *rand.Rand) (float64, bool) {
+	p := sampleMomentum(invMass, rng)
+	h0 := -s.lp + kineticEnergy(p, invMass)
+
+	proposal := s.clone()
+	for step := 0; step < steps; step++ {
+		leapfrog(density, proposal, p, eps, invMass)
+		if math.IsInf(proposal.lp, -1) || math.IsNaN(proposal.lp) {
+			break
+		}
+	}
+
+	h1 := -proposal.lp + kineticEnergy(p, invMass)
+	if math.IsNaN(h1) {
+		h1 = math.Inf(1)
+	}
+	divergent := h1-h0 > maxEnergyError
+	acceptance := math.Min(1, math.Exp(h0-h1))
+	if rng.Float64() < acceptance {
+		*s = *proposal
+	}
+	return acceptance, divergent
+}
+
+// phaseState is a position with its log density and gradient
+type phaseState struct {
+	x    []float64
+	grad []float64
+	lp   float64
+}
+
+// newPhaseState evaluates the density at x
+func newPhaseState(density DifferentiableDensity, x []float64) *phaseState {
+	s := &phaseState{x: append([]float64(nil), x...), grad: make([]float64, len(x))}
+	s.lp = density.LogDensityGradient(s.x, s.grad)
+	return s
+}
+
+// clone returns a deep copy of the state
+func (s *phaseState) clone() *phaseState {
+	return &phaseState{
+		x:    append([]float64(nil), s.x...),
+		grad: append([]float64(nil), s.grad...),
+		lp:   s.lp,
+	}
+}
+
+// leapfrog advances the state and momentum by one step of size eps
+func leapfrog(density DifferentiableDensity, s *phaseState, p []float64, eps float64, invMass []float64) {
+	for i := range p {
+		p[i] += eps / 2 * s.grad[i]
+	}
+	for i := range s.x {
+		s.x[i] += eps * invMass[i] * p[i]
+	}
+	s.lp = density.LogDensityGradient(s.x, s.grad)
+	for i := range p {
+		p[i] += eps / 2 * s.grad[i]
+	}
+}
+
+// sampleMomentum draws a momentum from Normal(0, M) with M = diag(1/invMass)
+func sampleMomentum(invMass []float64, rng *rand.Rand) []float64 {
+	p := make([]float64, len(invMass))
+	for i := range p {
+		p[i] = rng.NormFloat64() / math.Sqrt(invMass[i])
+	}
+	return p
+}
+
+// kineticEnergy returns ½ pᵀM⁻¹p
+func kineticEnergy(p, invMass []float64) float64 {
+	k := 0.0
+	for i, v := range p {
+		k += invMass[i] * v * v
+	}
+	return k / 2
+}
+
+// transitionFunc performs one sampler iteration in place, returning its
+// acceptance statistic and whether it diverged
+type transitionFunc func(s *phaseState, eps float64, invMass []float64, rng *rand.Rand) (float64, bool)
+
+// adaptiveSettings configures the warmup shared by the gradient-based samplers
+type adaptiveSettings struct {
+	density          DifferentiableDensity
+	chains           int
+	samples          int
+	warmup           int
+	stepSize         float64
+	targetAcceptance float64
+	adaptMassMatrix  bool
+	src              rand.Source
+}
+
+// run validates the starting point and runs every chain
+func (a adaptiveSettings) run(initial []float64, transition transitionFunc) (*Trace, error) {
+	if len(initial) == 0 {
+		return nil, fmt.Errorf("initial point must have at least one dimension")
+	}
+	start := newPhaseState(a.density, initial)
+	if !finiteState(start) {
+		return nil, fmt.Errorf("log density or gradient at initial point is not finite")
+	}
+
+	trace := newTrace(len(initial), a.chains, a.samples)
+	trace.Divergences = make([]int, a.chains)
+	trace.StepSize = make([]float64, a.chains)
+	logDensity := func(x []float64) float64 {
+		return newPhaseState(a.density, x).lp
+	}
+	for c, rng := range chainRands(a.src, a.chains) {
+		s := newPhaseState(a.density, jitter(logDensity, initial, rng))
+		if !finiteState(s) {
+			s = start.clone()
+		}
+		a.runChain(trace, c, s, transition, rng)
+	}
+	return trace, nil
+}
+
+// runChain adapts the step size and mass matrix during warmup, then records
+// the post-warmup draws, acceptance rate and divergences of one chain
+func (a adaptiveSettings) runChain(trace *Trace, chain int, s *phaseState, transition transitionFunc, rng *rand.Rand) {
+	dim := len(s.x)
+	invMass := make([]float64, dim)
+	for i := range invMass {
+		invMass[i] = 1
+	}
+
+	eps := a.stepSize
+	if eps <= 0 {
+		eps = initialStepSize(a.density, s, invMass, rng)
+	}
+	adapter := newDualAveraging(eps, a.targetAcceptance)
+	windowStart, windowEnds := adaptationWindows(a.warmup)
+	variance := newWelford(dim)
+
+	for iter := 0; iter < a.warmup; iter++ {
+		accept, _ := transition(s, eps, invMass, rng)
+		eps = adapter.update(accept)
+
+		if !a.adaptMassMatrix || iter < windowStart || len(windowEnds) == 0 {
+			continue
+		}
+		variance.add(s.x)
+		if iter+1 == windowEnds[0] {
+			windowEnds = windowEnds[1:]
+			variance.regularizedVariance(invMass)
+			variance = newWelford(dim)
+			eps = initialStepSize(a.density, s, invMass, rng)
+			adapter = newDualAveraging(eps, a.targetAcceptance)
+		}
+	}
+	if a.warmup > 0 {
+		eps = adapter.final()
+	}
+	trace.StepSize[chain] = eps
+
+	totalAccept := 0.0
+	for iter := 0; iter < a.samples; iter++ {
+		accept, divergent := transition(s, eps, invMass, rng)
+		totalAccept += accept
+		if divergent {
+			trace.Divergences[chain]++
+		}
+		trace.record(chain, s.x)
+	}
+	if a.samples > 0 {
+		trace.AcceptanceRate[chain] = totalAccept / float64(a.samples)
+	}
+}
+
+// finiteState reports whether the log density and gradient are finite
+func finiteState(s *phaseState) bool {
+	if math.IsNaN(s.lp) || math.IsInf(s.lp, 0) {
+		return false
+	}
+	for _, g := range s.grad {
+		if math.IsNaN(g) || math.IsInf(g, 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// initialStepSize doubles or halves the step size until the acceptance
+// probability of a single leapfrog step crosses one half
+func initialStepSize(density DifferentiableDensity, s *phaseState, invMass []float64, rng *rand.Rand) float64 {
+	eps := 1.0
+	logAccept := func() float64 {
+		p := sampleMomentum(invMass, rng)
+		h0 := -s.lp + kineticEnergy(p, invMass)
+		proposal := s.clone()
+		leapfrog(density, proposal, p, eps, invMass)
+		h1 := -proposal.lp + kineticEnergy(p, invMass)
+		if math.IsNaN(h1) {
+			return math.Inf(-1)
+		}
+		return h0 - h1
+	}
+
+	direction := 1.0
+	if logAccept() < math.Log(0.5) {
+		direction = -1
+	}
+	for i := 0; i < 100; i++ {
+		if direction*logAccept() <= -direction*math.Log(2) {
+			break
+		}
+		eps *= math.Pow(2, direction)
+	}
+	return eps
+}
+
+// dualAveraging tunes the step size so the mean acceptance statistic
+// approaches the target (Hoffman and Gelman, 2014)
+type dualAveraging struct {
+	target    float64
+	mu        float64
+	hBar      float64
+	logEpsBar float64
+	t         float64
+}
+
+// newDualAveraging starts adaptation from the step size eps
+func newDualAveraging(eps, target float64) *dualAveraging {
+	return &dualAveraging{target: target, mu: math.Log(10 * eps)}
+}
+
+// update records an acceptance statistic and returns the next step size
+func (d *dualAveraging) update(accept float64) float64 {
+	const gamma, t0, kappa = 0.05, 10, 0.75
+	d.t++
+	eta := 1 / (d.t + t0)
+	d.hBar = (1-eta)*d.hBar + eta*(d.target-accept)
+	logEps := d.mu - math.Sqrt(d.t)/gamma*d.hBar
+	w := math.Pow(d.t, -kappa)
+	d.logEpsBar = w*logEps + (1-w)*d.logEpsBar
+	return math.Exp(logEps)
+}
+
+// final returns the averaged step size used after warmup
+func (d *dualAveraging) final() float64 {
+	return math.Exp(d.logEpsBar)
+}
+
+// adaptationWindows returns the iteration at which mass matrix estimation
+// starts and the ends of its doubling windows, following Stan's schedule of a
+// 75 iteration initial buffer, 25 iteration first window and 50 iteration
+// terminal buffer, scaled down for short warmups
+func adaptationWindows(warmup int) (start int, ends []int) {
+	initBuffer, window, termBuffer := 75, 25, 50
+	if warmup < 20 {
+		return warmup, nil
+	}
+	if initBuffer+window+termBuffer > warmup {
+		initBuffer = warmup * 15 / 100
+		termBuffer = warmup / 10
+		window = warmup - initBuffer - termBuffer
+	}
+
+	last := warmup - termBuffer
+	for end := initBuffer + window; end <= last; end += window {
+		// Stretch a window that would leave too little room for the next one
+		if next := end + 2*window; next > last {
+			end = last
+		}
+		ends = append(ends, end)
+		window *= 2
+		if end == last {
+			break
+		}
+	}
+	return initBuffer, ends
+}
+
+// welford accumulates running means and variances
+type welford struct {
+	n    float64
+	mean []float64
+	m2   []float64
+}
+
+// newWelford creates an accumulator for dim dimensions
+func newWelford(dim int) *welford {
+	return &welford{mean: make([]float64, dim), m2: make([]float64, dim)}
+}
+
+// add includes an observation
+func (w *welford) add(x []float64) {
+	w.n++
+	for i, v := range x {
+		delta := v - w.mean[i]
+		w.mean[i] += delta / w.n
+		w.m2[i] += delta * (v - w.mean[i])
+	}
+}
+
+// regularizedVariance stores the sample variances, shrunk towards 1e-3 as
+// Stan does, in dst
+func (w *welford) regularizedVariance(dst []float64) {
+	if w.n < 2 {
+		return
+	}
+	for i := range dst {
+		variance := w.m2[i] / (w.n - 1)
+		dst[i] = (w.n/(w.n+5))*variance + 1e-3*(5/(w.n+5))
+	}
+}
@@ -0,0 +1,287 @@
+package inference
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// gaussian is a multivariate normal target with a known mean and covariance
+type gaussian struct {
+	mean       []float64
+	covariance *mat.SymDense
+	precision  *mat.SymDense
+}
+
+// newGaussian builds a target from standard deviations and the correlation
+// of its first two coordinates
+func newGaussian(mean, sd []float64, rho float64) *gaussian {
+	n := len(mean)
+	cov := mat.NewSymDense(n, nil)
+	for i := range n {
+		cov.SetSym(i, i, sd[i]*sd[i])
+	}
+	cov.SetSym(0, 1, rho*sd[0]*sd[1])
+
+	var chol mat.Cholesky
+	if !chol.Factorize(cov) {
+		panic("covariance is not positive definite")
+	}
+	precision := mat.NewSymDense(n, nil)
+	if err := chol.InverseTo(precision); err != nil {
+		panic(err)
+	}
+	return &gaussian{mean: mean, covariance: cov, precision: precision}
+}
+
+func (g *gaussian) LogDensityGradient(x, grad []float64) float64 {
+	diff := make([]float64, len(x))
+	for i := range x {
+		diff[i] = x[i] - g.mean[i]
+	}
+	pd := mat.NewVecDense(len(x), nil)
+	pd.MulVec(g.precision, mat.NewVecDense(len(x), diff))
+	lp := 0.0
+	for i := range x {
+		grad[i] = -pd.AtVec(i)
+		lp -= 0.5 * diff[i] * pd.AtVec(i)
+	}
+	return lp
+}
+
+// checkGaussian compares the draws of every parameter with the target's mean,
+// within five Monte Carlo standard errors, and its variance
+func checkGaussian(t *testing.T, trace *Trace, g *gaussian) {
+	t.Helper()
+	for p := range trace.Dim() {
+		chains := trace.Chains(p)
+		summary := trace.Summary(p)
+		mcse := MCSE(chains)
+		if math.Abs(summary.Mean-g.mean[p]) > 5*mcse {
+			t.Errorf("param %d: mean = %.4f, want %.4f (MCSE %.4f)", p, summary.Mean, g.mean[p], mcse)
+		}
+		if want := g.covariance.At(p, p); math.Abs(summary.Variance/want-1) > 0.15 {
+			t.Errorf("param %d: variance = %.4f, want %.4f", p, summary.Variance, want)
+		}
+		if r := RHat(chains); r > 1.01 {
+			t.Errorf("param %d: R-hat = %.3f", p, r)
+		}
+	}
+}
+
+// funnel returns Neal's funnel: v ~ Normal(0, 3) and x_i ~ Normal(0, exp(v/2)).
+// Its narrow neck defeats any single step size, so samplers should diverge.
+func funnel() GradientFunc {
+	return func(x, grad []float64) float64 {
+		v := x[0]
+		lp := -v * v / 18
+		grad[0] = -v / 9
+		for i := 1; i < len(x); i++ {
+			lp += -0.5*x[i]*x[i]*math.Exp(-v) - 0.5*v
+			grad[0] += 0.5*x[i]*x[i]*math.Exp(-v) - 0.5
+			grad[i] = -x[i] * math.Exp(-v)
+		}
+		return lp
+	}
+}
+
+func TestHMCRecoversGaussian(t *testing.T) {
+	g := newGaussian([]float64{1, -2, 0.5}, []float64{1, 3, 0.5}, 0.6)
+	hmc := NewHMC(g)
+	hmc.SetSource(rand.NewPCG(1, 2))
+	trace, err := hmc.Sample([]float64{0, 0, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkGaussian(t, trace, g)
+	for c, d := range trace.Divergences {
+		if d > 0 {
+			t.Errorf("chain %d: %d divergences", c, d)
+		}
+	}
+}
+
+func TestHMCIsReproducible(t *testing.T) {
+	g := newGaussian([]float64{0, 0}, []float64{1, 1}, 0)
+	run := func() *Trace {
+		hmc := NewHMC(g)
+		hmc.Chains, hmc.Samples, hmc.Warmup = 2, 50, 50
+		hmc.SetSource(rand.NewPCG(3, 4))
+		trace, err := hmc.Sample([]float64{0, 0})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return trace
+	}
+	a, b := run(), run()
+	for c := range a.NumChains() {
+		for i, v := range a.Values[0][c] {
+			if b.Values[0][c][i] != v {
+				t.Fatalf("chain %d draw %d differs: %v != %v", c, i, v, b.Values[0][c][i])
+			}
+		}
+	}
+}
+
+// checkStepSizeAdaptation runs a sampler at two acceptance targets. Dual
+// averaging should land every chain near its target, erring towards smaller
+// steps as the averaged step size does, and a higher target should give
+// smaller steps.
+func checkStepSizeAdaptation(t *testing.T, sample func(target float64) *Trace) {
+	t.Helper()
+	targets := []float64{0.8, 0.95}
+	meanStep := make([]float64, len(targets))
+	for i, target := range targets {
+		trace := sample(target)
+		for c, rate := range trace.AcceptanceRate {
+			if rate < target-0.05 || rate > target+0.15 {
+				t.Errorf("target %.2f: chain %d acceptance = %.3f", target, c, rate)
+			}
+			meanStep[i] += trace.StepSize[c] / float64(trace.NumChains())
+		}
+	}
+	if meanStep[1] >= meanStep[0] {
+		t.Errorf("step size %.3f at target %.2f is not below %.3f at %.2f",
+			meanStep[1], targets[1], meanStep[0], targets[0])
+	}
+}
+
+func TestHMCStepSizeAdaptsToTarget(t *testing.T) {
+	g := newGaussian([]float64{0, 0}, []float64{1, 1}, 0)
+	checkStepSizeAdaptation(t, func(target float64) *Trace {
+		hmc := NewHMC(g)
+		hmc.Steps = 5
+		hmc.TargetAcceptance = target
+		hmc.SetSource(rand.NewPCG(5, 6))
+		trace, err := hmc.Sample([]float64{0, 0})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return trace
+	})
+}
+
+func TestHMCMassMatrixAdaptsToScales(t *testing.T) {
+	// With scales 0.1 and 10, a unit mass matrix forces a step size below
+	// the smallest scale; an adapted mass matrix rescales both coordinates to
+	// unit variance, so the step size can grow to the unit-scale optimum.
+	g := newGaussian([]float64{0, 0}, []float64{0.1, 10}, 0)
+	stepSize := func(adapt bool) float64 {
+		hmc := NewHMC(g)
+		hmc.Chains = 1
+		hmc.AdaptMassMatrix = adapt
+		hmc.SetSource(rand.NewPCG(7, 8))
+		trace, err := hmc.Sample([]float64{0, 0})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return trace.StepSize[0]
+	}
+
+	fixed, adapted := stepSize(false), stepSize(true)
+	if fixed > 0.2 {
+		t.Errorf("step size with unit mass = %.3f, want below 0.2", fixed)
+	}
+	if adapted < 0.3 || adapted < 5*fixed {
+		t.Errorf("step size with adapted mass = %.3f, want near unit scale (unit mass gave %.3f)", adapted, fixed)
+	}
+}
+
+func TestWelfordRegularizedVariance(t *testing.T) {
+	rng := rand.New(rand.NewPCG(9, 10))
+	sd := []float64{0.1, 1, 10}
+	w := newWelford(len(sd))
+	x := make([]float64, len(sd))
+	for range 5000 {
+		for i := range x {
+			x[i] = 3 + sd[i]*rng.NormFloat64()
+		}
+		w.add(x)
+	}
+
+	variance := []float64{1, 1, 1}
+	w.regularizedVariance(variance)
+	for i, s := range sd {
+		if want := s * s; math.Abs(variance[i]/want-1) > 0.1 {
+			t.Errorf("variance[%d] = %.4f, want %.4f", i, variance[i], want)
+		}
+	}
+
+	short := newWelford(1)
+	short.add([]float64{1})
+	unchanged := []float64{2}
+	short.regularizedVariance(unchanged)
+	if unchanged[0] != 2 {
+		t.Errorf("variance from one draw = %v, want it left unchanged", unchanged[0])
+	}
+}
+
+func TestDualAveragingConverges(t *testing.T) {
+	// Acceptance falls smoothly with the step size and equals 0.8 at 0.5
+	accept := func(eps float64) float64 { return math.Pow(0.8, eps*eps/0.25) }
+	d := newDualAveraging(4, 0.8)
+	eps := 4.0
+	for range 2000 {
+		eps = d.update(accept(eps))
+	}
+	if got := d.final(); math.Abs(got-0.5) > 0.02 {
+		t.Errorf("final step size = %.4f, want 0.5", got)
+	}
+}
+
+func TestAdaptationWindows(t *testing.T) {
+	start, ends := adaptationWindows(1000)
+	if start != 75 {
+		t.Errorf("start = %d, want 75", start)
+	}
+	want := []int{100, 150, 250, 450, 950}
+	if len(ends) != len(want) {
+		t.Fatalf("ends = %v, want %v", ends, want)
+	}
+	for i := range want {
+		if ends[i] != want[i] {
+			t.Fatalf("ends = %v, want %v", ends, want)
+		}
+	}
+
+	start, ends = adaptationWindows(100)
+	if start != 15 || len(ends) != 1 || ends[0] != 90 {
+		t.Errorf("adaptationWindows(100) = %d, %v; want 15, [90]", start, ends)
+	}
+	if start, ends = adaptationWindows(10); start != 10 || ends != nil {
+		t.Errorf("adaptationWindows(10) = %d, %v; want no windows", start, ends)
+	}
+}
+
+func TestHMCRejectsNonFiniteStart(t *testing.T) {
+	density := GradientFunc(func(x, grad []float64) float64 {
+		grad[0] = 0
+		return math.Log(x[0])
+	})
+	if _, err := NewHMC(density).Sample([]float64{-1}); err == nil {
+		t.Error("Sample from a point of zero density succeeded, want error")
+	}
+	if _, err := NewHMC(density).Sample(nil); err == nil {
+		t.Error("Sample with no dimensions succeeded, want error")
+	}
+}
+
+func TestHMCReportsDivergencesOnFunnel(t *testing.T) {
+	hmc := NewHMC(funnel())
+	hmc.Chains, hmc.Samples, hmc.Warmup = 2, 500, 500
+	hmc.SetSource(rand.NewPCG(11, 12))
+	trace, err := hmc.Sample(make([]float64, 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	total := 0
+	for _, d := range trace.Divergences {
+		total += d
+	}
+	if total == 0 {
+		t.Error("no divergences reported on Neal's funnel")
+	}
+}
@@ -0,0 +1,99 @@
+// Package inference provides Markov chain Monte Carlo samplers for models
+// whose posteriors have no conjugate closed form.
+package inference
+
+import (
+	"math/rand/v2"
+
+	"github.com/MyVueCodeHub/myvue-bayes/distributions"
+)
+
+// LogDensity returns the unnormalized log density of a parameter vector.
+// Points outside the support should return math.Inf(-1).
+type LogDensity func(x []float64) float64
+
+// Sampler draws posterior samples starting from an initial parameter vector
+type Sampler interface {
+	Sample(initial []float64) (*Trace, error)
+}
+
+// Trace holds the draws of every chain of a sampler run
+type Trace struct {
+	// Values holds the draws indexed by parameter, chain and iteration
+	Values [][][]float64
+	// AcceptanceRate is the post-warmup acceptance rate of each chain
+	AcceptanceRate []float64
+	// Divergences counts the post-warmup divergent transitions of each chain
+	// for gradient-based samplers; any divergence means the draws may be biased
+	Divergences []int
+	// StepSize is the adapted step size of each chain for gradient-based samplers
+	StepSize []float64
+}
+
+// newTrace allocates a trace for dim parameters, chains chains and n draws each
+func newTrace(dim, chains, n int) *Trace {
+	t := &Trace{
+		Values:         make([][][]float64, dim),
+		AcceptanceRate: make([]float64, chains),
+	}
+	for p := range t.Values {
+		t.Values[p] = make([][]float64, chains)
+		for c := range t.Values[p] {
+			t.Values[p][c] = make([]float64, 0, n)
+		}
+	}
+	return t
+}
+
+// record appends a draw to the given chain
+func (t *Trace) record(chain int, x []float64) {
+	for p, v := range x {
+		t.Values[p][chain] = append(t.Values[p][chain], v)
+	}
+}
+
+// Dim returns the number of parameters
+func (t *Trace) Dim() int {
+	return len(t.Values)
+}
+
+// NumChains returns the number of chains
+func (t *Trace) NumChains() int {
+	if len(t.Values) == 0 {
+		return 0
+	}
+	return len(t.Values[0])
+}
+
+// Chains returns the per-chain draws of a parameter, as used by TracePlot
+func (t *Trace) Chains(param int) [][]float64 {
+	return t.Values[param]
+}
+
+// Samples returns the draws of a parameter pooled across chains
+func (t *Trace) Samples(param int) []float64 {
+	var samples []float64
+	for _, chain := range t.Values[param] {
+		samples = append(samples, chain...)
+	}
+	return samples
+}
+
+// Summary summarizes the pooled draws of a parameter
+func (t *Trace) Summary(param int) distributions.Summary {
+	return distributions.ComputeSummary(t.Samples(param))
+}
+
+// chainRands returns an independent generator for each chain, derived from
+// src when it is set so that runs are reproducible
+func chainRands(src rand.Source, chains int) []*rand.Rand {
+	seeds := rand.Uint64
+	if src != nil {
+		seeds = rand.New(src).Uint64
+	}
+	rngs := make([]*rand.Rand, chains)
+	for c := range rngs {
+		rngs[c] = rand.New(rand.NewPCG(seeds(), seeds()))
+	}
+	return rngs
+}
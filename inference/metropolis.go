@@ -0,0 +1,155 @@
+package inference
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+)
+
+// maxInitAttempts bounds the jittered starting points tried for each chain
+const maxInitAttempts = 100
+
+// MetropolisHastings is a random-walk Metropolis-Hastings sampler with
+// Gaussian proposals. During burn-in it adapts a global proposal scale
+// towards TargetAcceptance by Robbins-Monro updates and, halfway through,
+// rescales each dimension by the standard deviation of the draws so far.
+type MetropolisHastings struct {
+	LogDensity LogDensity
+
+	Chains  int // number of independent chains
+	Samples int // draws kept per chain after burn-in and thinning
+	BurnIn  int // iterations discarded at the start of each chain
+	Thin    int // keep every Thin-th draw
+
+	// Adapt enables proposal adaptation during burn-in
+	Adapt bool
+	// Scale is the initial standard deviation of the proposal
+	Scale float64
+	// TargetAcceptance is the acceptance rate adaptation aims for
+	TargetAcceptance float64
+
+	// Src, when set, makes the chains reproducible
+	Src rand.Source
+}
+
+// NewMetropolisHastings creates a sampler with 4 chains of 1000 draws after
+// 1000 burn-in iterations, and adaptation towards a 23.4% acceptance rate
+func NewMetropolisHastings(logDensity LogDensity) *MetropolisHastings {
+	return &MetropolisHastings{
+		LogDensity:       logDensity,
+		Chains:           4,
+		Samples:          1000,
+		BurnIn:           1000,
+		Thin:             1,
+		Adapt:            true,
+		Scale:            1,
+		TargetAcceptance: 0.234,
+	}
+}
+
+// SetSource sets the random source used to seed the chains
+func (mh *MetropolisHastings) SetSource(src rand.Source) {
+	mh.Src = src
+}
+
+// Sample runs the chains from jittered copies of initial. It returns an error
+// if the log density is not finite at initial.
+func (mh *MetropolisHastings) Sample(initial []float64) (*Trace, error) {
+	if len(initial) == 0 {
+		return nil, fmt.Errorf("initial point must have at least one dimension")
+	}
+	if lp := mh.LogDensity(initial); math.IsNaN(lp) || math.IsInf(lp, 0) {
+		return nil, fmt.Errorf("log density at initial point is %v", lp)
+	}
+
+	thin := max(mh.Thin, 1)
+	trace := newTrace(len(initial), mh.Chains, mh.Samples)
+	for c, rng := range chainRands(mh.Src, mh.Chains) {
+		trace.AcceptanceRate[c] = mh.runChain(trace, c, jitter(mh.LogDensity, initial, rng), thin, rng)
+	}
+	return trace, nil
+}
+
+// runChain runs one chain, records its kept draws and returns the
+// post-burn-in acceptance rate
+func (mh *MetropolisHastings) runChain(trace *Trace, chain int, x []float64, thin int, rng *rand.Rand) float64 {
+	dim := len(x)
+	lp := mh.LogDensity(x)
+	logScale := math.Log(mh.Scale)
+	stdDevs := make([]float64, dim)
+	for i := range stdDevs {
+		stdDevs[i] = 1
+	}
+
+	// Running moments of the burn-in draws for per-dimension scaling
+	mean := make([]float64, dim)
+	m2 := make([]float64, dim)
+
+	proposal := make([]float64, dim)
+	accepted := 0
+	total := mh.BurnIn + mh.Samples*thin
+	for iter := 0; iter < total; iter++ {
+		scale := math.Exp(logScale)
+		for i := range proposal {
+			proposal[i] = x[i] + scale*stdDevs[i]*rng.NormFloat64()
+		}
+
+		acceptance := 0.0
+		if lpNew := mh.LogDensity(proposal); !math.IsNaN(lpNew) {
+			acceptance = math.Min(1, math.Exp(lpNew-lp))
+			if rng.Float64() < acceptance {
+				copy(x, proposal)
+				lp = lpNew
+				if iter >= mh.BurnIn {
+					accepted++
+				}
+			}
+		}
+
+		if iter < mh.BurnIn {
+			if mh.Adapt {
+				logScale += (acceptance - mh.TargetAcceptance) / math.Pow(float64(iter+1), 0.6)
+				n := float64(iter + 1)
+				for i, v := range x {
+					delta := v - mean[i]
+					mean[i] += delta / n
+					m2[i] += delta * (v - mean[i])
+				}
+				if iter+1 == mh.BurnIn/2 && n > 1 {
+					for i := range stdDevs {
+						if sd := math.Sqrt(m2[i] / (n - 1)); sd > 0 {
+							stdDevs[i] = sd
+						}
+					}
+					logScale = math.Log(2.38 / math.Sqrt(float64(dim)))
+				}
+			}
+			continue
+		}
+
+		if (iter-mh.BurnIn+1)%thin == 0 {
+			trace.record(chain, x)
+		}
+	}
+
+	if mh.Samples == 0 {
+		return 0
+	}
+	return float64(accepted) / float64(mh.Samples*thin)
+}
+
+// jitter returns a starting point near initial with a finite log density,
+// falling back to initial itself
+func jitter(logDensity LogDensity, initial []float64, rng *rand.Rand) []float64 {
+	x := make([]float64, len(initial))
+	for attempt := 0; attempt < maxInitAttempts; attempt++ {
+		for i, v := range initial {
+			x[i] = v + 0.1*(math.Abs(v)+1)*(2*rng.Float64()-1)
+		}
+		if lp := logDensity(x); !math.IsNaN(lp) && !math.IsInf(lp, 0) {
+			return x
+		}
+	}
+	copy(x, initial)
+	return x
+}
@@ -0,0 +1,234 @@
+package inference
+
+import (
+	"math"
+	"math/rand/v2"
+)
+
+// NUTS is the No-U-Turn sampler: an HMC variant that grows each trajectory
+// by repeated doubling until it starts to turn back on itself, so the path
+// length need not be tuned. This implementation draws the next state from
+// the trajectory in proportion to its density (multinomial sampling) and
+// shares its warmup, step-size and mass-matrix adaptation with HMC.
+type NUTS struct {
+	Density DifferentiableDensity
+
+	Chains  int // number of independent chains
+	Samples int // draws kept per chain after warmup
+	Warmup  int // adaptation iterations discarded at the start of each chain
+
+	// MaxTreeDepth caps each trajectory at 2^MaxTreeDepth - 1 leapfrog steps;
+	// values below 1 are treated as 1, a single step
+	MaxTreeDepth int
+	// StepSize is the initial leapfrog step size; zero picks one heuristically
+	StepSize float64
+	// TargetAcceptance is the mean acceptance statistic adaptation aims for
+	TargetAcceptance float64
+	// AdaptMassMatrix enables diagonal mass matrix adaptation during warmup
+	AdaptMassMatrix bool
+
+	// Src, when set, makes the chains reproducible
+	Src rand.Source
+}
+
+// NewNUTS creates a sampler with 4 chains of 1000 draws after 1000 warmup
+// iterations, a maximum tree depth of 10 and an 80% target acceptance rate
+func NewNUTS(density DifferentiableDensity) *NUTS {
+	return &NUTS{
+		Density:          density,
+		Chains:           4,
+		Samples:          1000,
+		Warmup:           1000,
+		MaxTreeDepth:     10,
+		TargetAcceptance: 0.8,
+		AdaptMassMatrix:  true,
+	}
+}
+
+// SetSource sets the random source used to seed the chains
+func (n *NUTS) SetSource(src rand.Source) {
+	n.Src = src
+}
+
+// Sample runs the chains from jittered copies of initial. It returns an error
+// if the log density or its gradient is not finite at initial.
+func (n *NUTS) Sample(initial []float64) (*Trace, error) {
+	settings := adaptiveSettings{
+		density:          n.Density,
+		chains:           n.Chains,
+		samples:          n.Samples,
+		warmup:           n.Warmup,
+		stepSize:         n.StepSize,
+		targetAcceptance: n.TargetAcceptance,
+		adaptMassMatrix:  n.AdaptMassMatrix,
+		src:              n.Src,
+	}
+	return settings.run(initial, n.transition)
+}
+
+// nutsTree is a balanced subtree of a NUTS trajectory
+type nutsTree struct {
+	minus, plus   *phaseState // leftmost and rightmost states in time
+	pMinus, pPlus []float64   // momenta at those states
+	proposal      *phaseState // state drawn from the subtree
+	logWeight     float64     // log of the summed densities of the subtree
+	rho           []float64   // sum of the momenta in the subtree
+	sumAccept     float64     // summed acceptance statistics of its states
+	n             int         // number of states
+	stop          bool        // the subtree made a U-turn or diverged
+	divergent     bool
+}
+
+// transition performs one NUTS iteration, returning the mean acceptance
+// statistic of the new states and whether the trajectory diverged
+func (n *NUTS) transition(s *phaseState, eps float64, invMass []float64, rng *rand.Rand) (float64, bool) {
+	p0 := sampleMomentum(invMass, rng)
+	h0 := -s.lp + kineticEnergy(p0, invMass)
+
+	tree := &nutsTree{
+		minus:    s,
+		plus:     s,
+		pMinus:   p0,
+		pPlus:    p0,
+		proposal: s,
+		rho:      append([]float64(nil), p0...),
+	}
+
+	maxDepth := max(n.MaxTreeDepth, 1)
+	sumAccept, count := 0.0, 0
+	divergent := false
+	for depth := 0; depth < maxDepth; depth++ {
+		var sub *nutsTree
+		if rng.Float64() < 0.5 {
+			sub = n.buildTree(tree.plus, tree.pPlus, eps, depth, h0, invMass, rng)
+			tree.plus, tree.pPlus = sub.plus, sub.pPlus
+		} else {
+			sub = n.buildTree(tree.minus, tree.pMinus, -eps, depth, h0, invMass, rng)
+			tree.minus, tree.pMinus = sub.minus, sub.pMinus
+		}
+		sumAccept += sub.sumAccept
+		count += sub.n
+		if sub.stop {
+			divergent = sub.divergent
+			break
+		}
+
+		// Favour the new subtree in proportion to its weight, which moves
+		// the draw away from the starting point (biased progressive sampling)
+		if rng.Float64() < math.Exp(sub.logWeight-tree.logWeight) {
+			tree.proposal = sub.proposal
+		}
+		tree.logWeight = logAddExp(tree.logWeight, sub.logWeight)
+		for i := range tree.rho {
+			tree.rho[i] += sub.rho[i]
+		}
+		if uTurn(tree.rho, tree.pMinus, tree.pPlus, invMass) {
+			break
+		}
+	}
+
+	*s = *tree.proposal
+	if count == 0 {
+		return 0, divergent
+	}
+	return sumAccept / float64(count), divergent
+}
+
+// buildTree builds a subtree of 2^depth leapfrog steps of size eps from the
+// state s with momentum p; a negative eps integrates backwards in time
+func (n *NUTS) buildTree(s *phaseState, p []float64, eps float64, depth int, h0 float64, invMass []float64, rng *rand.Rand) *nutsTree {
+	if depth == 0 {
+		next := s.clone()
+		pNext := append([]float64(nil), p...)
+		leapfrog(n.Density, next, pNext, eps, invMass)
+
+		h := -next.lp + kineticEnergy(pNext, invMass)
+		if math.IsNaN(h) {
+			h = math.Inf(1)
+		}
+		divergent := h-h0 > maxEnergyError
+		return &nutsTree{
+			minus:     next,
+			plus:      next,
+			pMinus:    pNext,
+			pPlus:     pNext,
+			proposal:  next,
+			logWeight: h0 - h,
+			rho:       append([]float64(nil), pNext...),
+			sumAccept: math.Min(1, math.Exp(h0-h)),
+			n:         1,
+			stop:      divergent,
+			divergent: divergent,
+		}
+	}
+
+	first := n.buildTree(s, p, eps, depth-1, h0, invMass, rng)
+	if first.stop {
+		return first
+	}
+
+	var second *nutsTree
+	if eps > 0 {
+		second = n.buildTree(first.plus, first.pPlus, eps, depth-1, h0, invMass, rng)
+	} else {
+		second = n.buildTree(first.minus, first.pMinus, eps, depth-1, h0, invMass, rng)
+	}
+
+	tree := &nutsTree{
+		sumAccept: first.sumAccept + second.sumAccept,
+		n:         first.n + second.n,
+	}
+	if second.stop {
+		tree.stop = true
+		tree.divergent = second.divergent
+		return tree
+	}
+
+	if eps > 0 {
+		tree.minus, tree.pMinus = first.minus, first.pMinus
+		tree.plus, tree.pPlus = second.plus, second.pPlus
+	} else {
+		tree.minus, tree.pMinus = second.minus, second.pMinus
+		tree.plus, tree.pPlus = first.plus, first.pPlus
+	}
+
+	// Within a subtree, states are drawn uniformly in proportion to weight
+	tree.logWeight = logAddExp(first.logWeight, second.logWeight)
+	tree.proposal = first.proposal
+	if rng.Float64() < math.Exp(second.logWeight-tree.logWeight) {
+		tree.proposal = second.proposal
+	}
+
+	tree.rho = make([]float64, len(first.rho))
+	for i := range tree.rho {
+		tree.rho[i] = first.rho[i] + second.rho[i]
+	}
+	tree.stop = uTurn(tree.rho, tree.pMinus, tree.pPlus, invMass)
+	return tree
+}
+
+// uTurn applies the generalized no-U-turn criterion: the trajectory is
+// turning back once the velocity at either end points against the summed
+// momentum rho
+func uTurn(rho, pMinus, pPlus, invMass []float64) bool {
+	minus, plus := 0.0, 0.0
+	for i, r := range rho {
+		minus += invMass[i] * pMinus[i] * r
+		plus += invMass[i] * pPlus[i] * r
+	}
+	return minus <= 0 || plus <= 0
+}
+
+// logAddExp returns log(exp(a) + exp(b)) without overflow
+func logAddExp(a, b float64) float64 {
+	if math.IsInf(a, -1) {
+		return b
+	}
+	if math.IsInf(b, -1) {
+		return a
+	}
+	if a < b {
+		a, b = b, a
+	}
+	return a + math.Log1p(math.Exp(b-a))
+}
@@ -0,0 +1,116 @@
+package inference
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestNUTSRecoversGaussian(t *testing.T) {
+	g := newGaussian([]float64{1, -2, 0.5}, []float64{1, 3, 0.5}, 0.6)
+	nuts := NewNUTS(g)
+	nuts.SetSource(rand.NewPCG(1, 2))
+	trace, err := nuts.Sample([]float64{0, 0, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkGaussian(t, trace, g)
+	for c, d := range trace.Divergences {
+		if d > 0 {
+			t.Errorf("chain %d: %d divergences", c, d)
+		}
+	}
+}
+
+func TestNUTSStepSizeAdaptsToTarget(t *testing.T) {
+	g := newGaussian([]float64{0, 0}, []float64{1, 1}, 0)
+	checkStepSizeAdaptation(t, func(target float64) *Trace {
+		nuts := NewNUTS(g)
+		nuts.TargetAcceptance = target
+		nuts.SetSource(rand.NewPCG(3, 4))
+		trace, err := nuts.Sample([]float64{0, 0})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return trace
+	})
+}
+
+func TestNUTSMassMatrixAdaptsToScales(t *testing.T) {
+	// Without a mass matrix matching the scales 0.1, 1 and 10, the step size
+	// would be limited by the smallest of them
+	g := newGaussian([]float64{0, 0, 0}, []float64{0.1, 1, 10}, 0)
+	nuts := NewNUTS(g)
+	nuts.SetSource(rand.NewPCG(3, 4))
+	trace, err := nuts.Sample([]float64{0, 0, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for c, eps := range trace.StepSize {
+		if eps < 0.3 {
+			t.Errorf("chain %d: step size = %.3f, want near unit scale", c, eps)
+		}
+	}
+	checkGaussian(t, trace, g)
+}
+
+func TestNUTSReportsDivergencesOnFunnel(t *testing.T) {
+	nuts := NewNUTS(funnel())
+	nuts.Chains, nuts.Samples, nuts.Warmup = 2, 500, 500
+	nuts.SetSource(rand.NewPCG(5, 6))
+	trace, err := nuts.Sample(make([]float64, 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	total := 0
+	for _, d := range trace.Divergences {
+		total += d
+	}
+	if total == 0 {
+		t.Error("no divergences reported on Neal's funnel")
+	}
+}
+
+func TestNUTSMaxTreeDepth(t *testing.T) {
+	// A depth of zero is treated as one, a single leapfrog step per
+	// iteration, which still samples a standard normal, only less efficiently
+	g := newGaussian([]float64{0, 0}, []float64{1, 1}, 0)
+	nuts := NewNUTS(g)
+	nuts.MaxTreeDepth = 0
+	nuts.SetSource(rand.NewPCG(7, 8))
+	trace, err := nuts.Sample([]float64{0, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ess := ESS(trace.Chains(0)); ess > 2000 {
+		t.Errorf("ESS = %.0f with one leapfrog step, want strongly autocorrelated draws", ess)
+	}
+	checkGaussian(t, trace, g)
+}
+
+func TestLogAddExp(t *testing.T) {
+	tests := []struct{ a, b, want float64 }{
+		{0, 0, math.Ln2},
+		{math.Log(2), math.Log(3), math.Log(5)},
+		{1000, 1000, 1000 + math.Ln2},
+		{math.Inf(-1), 2, 2},
+		{2, math.Inf(-1), 2},
+	}
+	for _, tt := range tests {
+		if got := logAddExp(tt.a, tt.b); math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("logAddExp(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestUTurn(t *testing.T) {
+	invMass := []float64{1, 1}
+	// rho is the summed momentum of the trajectory
+	if uTurn([]float64{2, 0}, []float64{1, 0}, []float64{1, 0}, invMass) {
+		t.Error("straight trajectory reported as a U-turn")
+	}
+	if !uTurn([]float64{0, 0.1}, []float64{1, 0}, []float64{-1, 0}, invMass) {
+		t.Error("reversing trajectory not reported as a U-turn")
+	}
+}
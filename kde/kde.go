@@ -0,0 +1,206 @@
+// Package kde provides kernel density estimation for posterior samples.
+package kde
+
+import (
+	"math"
+	"sort"
+)
+
+// Kernel represents the smoothing kernel of a density estimate
+type Kernel int
+
+const (
+	// Gaussian is the standard normal kernel
+	Gaussian Kernel = iota
+	// Epanechnikov is the parabolic kernel with bounded support, which is
+	// optimal in the mean integrated squared error sense
+	Epanechnikov
+)
+
+// epanechnikovScale converts a Gaussian-kernel bandwidth into the equivalent
+// Epanechnikov bandwidth using the ratio of their canonical bandwidths
+const epanechnikovScale = 2.2138
+
+// KDE represents a kernel density estimate
+type KDE struct {
+	Samples   []float64 // sorted by value
+	Weights   []float64 // aligned with Samples; nil for equal weights
+	Kernel    Kernel
+	Bandwidth float64
+
+	// Lower and Upper bound the support. Mass that the kernels place beyond a
+	// finite bound is reflected back inside it.
+	Lower float64
+	Upper float64
+
+	totalWeight float64
+}
+
+// New creates a kernel density estimate with bandwidth chosen by method.
+// weights may be nil for equally weighted samples.
+func New(samples, weights []float64, kernel Kernel, method BandwidthMethod) *KDE {
+	var bandwidth float64
+	switch method {
+	case Scott:
+		bandwidth = ScottBandwidth(samples, weights)
+	case ISJ:
+		bandwidth = ISJBandwidth(samples, weights)
+	default:
+		bandwidth = SilvermanBandwidth(samples, weights)
+	}
+	if kernel == Epanechnikov {
+		bandwidth *= epanechnikovScale
+	}
+	return NewWithBandwidth(samples, weights, kernel, bandwidth)
+}
+
+// NewWithBandwidth creates a kernel density estimate with a fixed bandwidth
+func NewWithBandwidth(samples, weights []float64, kernel Kernel, bandwidth float64) *KDE {
+	sorted, sortedWeights := sortWeighted(samples, weights)
+
+	total := float64(len(sorted))
+	if sortedWeights != nil {
+		total = 0
+		for _, w := range sortedWeights {
+			total += w
+		}
+	}
+
+	return &KDE{
+		Samples:     sorted,
+		Weights:     sortedWeights,
+		Kernel:      kernel,
+		Bandwidth:   bandwidth,
+		Lower:       math.Inf(-1),
+		Upper:       math.Inf(1),
+		totalWeight: total,
+	}
+}
+
+// SetBounds restricts the support to [lower, upper] and enables reflection
+// boundary correction. Use math.Inf for an unbounded side.
+func (k *KDE) SetBounds(lower, upper float64) *KDE {
+	k.Lower = lower
+	k.Upper = upper
+	return k
+}
+
+// PDF returns the estimated density at x
+func (k *KDE) PDF(x float64) float64 {
+	if x < k.Lower || x > k.Upper || len(k.Samples) == 0 {
+		return 0
+	}
+
+	density := k.raw(x)
+	if !math.IsInf(k.Lower, -1) {
+		density += k.raw(2*k.Lower - x)
+	}
+	if !math.IsInf(k.Upper, 1) {
+		density += k.raw(2*k.Upper - x)
+	}
+	return density
+}
+
+// Evaluate returns the estimated density at each point of xs
+func (k *KDE) Evaluate(xs []float64) []float64 {
+	ys := make([]float64, len(xs))
+	for i, x := range xs {
+		ys[i] = k.PDF(x)
+	}
+	return ys
+}
+
+// Grid evaluates the density on n equally spaced points covering the samples
+// plus three bandwidths on either side, clipped to the support
+func (k *KDE) Grid(n int) (xs, ys []float64) {
+	if len(k.Samples) == 0 || n < 2 {
+		return nil, nil
+	}
+
+	lo := math.Max(k.Samples[0]-3*k.Bandwidth, k.Lower)
+	hi := math.Min(k.Samples[len(k.Samples)-1]+3*k.Bandwidth, k.Upper)
+
+	xs = make([]float64, n)
+	step := (hi - lo) / float64(n-1)
+	for i := range xs {
+		xs[i] = lo + float64(i)*step
+	}
+	return xs, k.Evaluate(xs)
+}
+
+// Mode returns the location of the highest estimated density
+func (k *KDE) Mode() float64 {
+	if len(k.Samples) == 0 {
+		return math.NaN()
+	}
+	if k.Bandwidth <= 0 || math.IsNaN(k.Bandwidth) {
+		return k.Samples[len(k.Samples)/2]
+	}
+
+	// Coarse search on a grid, then refine between the neighbouring grid points
+	xs, ys := k.Grid(256)
+	best := 0
+	for i, y := range ys {
+		if y > ys[best] {
+			best = i
+		}
+	}
+
+	a := xs[max(best-1, 0)]
+	b := xs[min(best+1, len(xs)-1)]
+	const invPhi = 0.6180339887498949
+	c := b - invPhi*(b-a)
+	d := a + invPhi*(b-a)
+	fc, fd := k.PDF(c), k.PDF(d)
+	for i := 0; i < 60 && b-a > 1e-12*math.Max(1, math.Abs(a)); i++ {
+		if fc > fd {
+			b, d, fd = d, c, fc
+			c = b - invPhi*(b-a)
+			fc = k.PDF(c)
+		} else {
+			a, c, fc = c, d, fd
+			d = a + invPhi*(b-a)
+			fd = k.PDF(d)
+		}
+	}
+
+	mode := (a + b) / 2
+	if k.PDF(mode) < ys[best] {
+		return xs[best]
+	}
+	return mode
+}
+
+// raw returns the unreflected kernel sum at x, visiting only the samples
+// within the kernel's effective support
+func (k *KDE) raw(x float64) float64 {
+	h := k.Bandwidth
+	reach := h
+	if k.Kernel == Gaussian {
+		reach = 6 * h
+	}
+
+	start := sort.SearchFloat64s(k.Samples, x-reach)
+	sum := 0.0
+	for i := start; i < len(k.Samples) && k.Samples[i] <= x+reach; i++ {
+		w := 1.0
+		if k.Weights != nil {
+			w = k.Weights[i]
+		}
+		sum += w * k.kernel((x-k.Samples[i])/h)
+	}
+	return sum / (k.totalWeight * h)
+}
+
+// kernel evaluates the standardized kernel at u
+func (k *KDE) kernel(u float64) float64 {
+	switch k.Kernel {
+	case Epanechnikov:
+		if math.Abs(u) >= 1 {
+			return 0
+		}
+		return 0.75 * (1 - u*u)
+	default:
+		return math.Exp(-0.5*u*u) / math.Sqrt(2*math.Pi)
+	}
+}
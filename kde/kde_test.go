@@ -0,0 +1,205 @@
+package kde
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+// normalSamples returns n draws from Normal(mu, sigma)
+func normalSamples(n int, mu, sigma float64, seed uint64) []float64 {
+	rng := rand.New(rand.NewPCG(seed, seed))
+	samples := make([]float64, n)
+	for i := range samples {
+		samples[i] = mu + sigma*rng.NormFloat64()
+	}
+	return samples
+}
+
+// integrate returns the trapezoidal integral of the density over [lo, hi]
+func integrate(k *KDE, lo, hi float64) float64 {
+	const n = 20000
+	step := (hi - lo) / n
+	sum := (k.PDF(lo) + k.PDF(hi)) / 2
+	for i := 1; i < n; i++ {
+		sum += k.PDF(lo + float64(i)*step)
+	}
+	return sum * step
+}
+
+func TestBandwidthsForGaussianData(t *testing.T) {
+	const n = 1000
+	samples := normalSamples(n, 0, 1, 1)
+	scale := math.Pow(n, -0.2)
+
+	// For a standard normal the rules reduce to constants times n^(-1/5), and
+	// the AMISE-optimal bandwidth is (4/3)^(1/5) n^(-1/5), which ISJ estimates
+	optimal := math.Pow(4.0/3, 0.2) * scale
+	tests := []struct {
+		name      string
+		got, want float64
+		tolerance float64
+	}{
+		{"Silverman", SilvermanBandwidth(samples, nil), 0.9 * scale, 0.05},
+		{"Scott", ScottBandwidth(samples, nil), 1.06 * scale, 0.05},
+		{"ISJ", ISJBandwidth(samples, nil), optimal, 0.2},
+	}
+	for _, tt := range tests {
+		if math.Abs(tt.got-tt.want) > tt.tolerance*tt.want {
+			t.Errorf("%s bandwidth = %v, want %v within %.0f%%", tt.name, tt.got, tt.want, 100*tt.tolerance)
+		}
+	}
+}
+
+func TestBandwidthsScaleWithData(t *testing.T) {
+	samples := normalSamples(500, 0, 1, 2)
+	scaled := make([]float64, len(samples))
+	for i, x := range samples {
+		scaled[i] = 10 + 3*x
+	}
+	for name, f := range map[string]func(samples, weights []float64) float64{
+		"Silverman": SilvermanBandwidth,
+		"Scott":     ScottBandwidth,
+		"ISJ":       ISJBandwidth,
+	} {
+		if got, want := f(scaled, nil), 3*f(samples, nil); math.Abs(got-want) > 1e-6*want {
+			t.Errorf("%s: bandwidth of 10 + 3x = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestBandwidthsOfDegenerateSamples(t *testing.T) {
+	tests := []struct {
+		name    string
+		samples []float64
+	}{
+		{"empty", nil},
+		{"single", []float64{4}},
+		{"single zero", []float64{0}},
+		{"constant", []float64{2.5, 2.5, 2.5, 2.5}},
+	}
+	for _, tt := range tests {
+		for name, f := range map[string]func(samples, weights []float64) float64{
+			"Silverman": SilvermanBandwidth,
+			"Scott":     ScottBandwidth,
+			"ISJ":       ISJBandwidth,
+		} {
+			if h := f(tt.samples, nil); !(h > 0) || math.IsInf(h, 1) {
+				t.Errorf("%s bandwidth of %s samples = %v, want positive and finite", name, tt.name, h)
+			}
+		}
+	}
+
+	k := New([]float64{3}, nil, Gaussian, ISJ)
+	if got := integrate(k, -40, 46); math.Abs(got-1) > 1e-6 {
+		t.Errorf("density of a single sample integrates to %v, want 1", got)
+	}
+	if got := k.Mode(); math.Abs(got-3) > 1e-6 {
+		t.Errorf("mode of a single sample = %v, want 3", got)
+	}
+}
+
+func TestDensityIntegratesToOne(t *testing.T) {
+	samples := normalSamples(400, 1, 2, 3)
+	rng := rand.New(rand.NewPCG(3, 3))
+	uniform := make([]float64, 400)
+	for i := range uniform {
+		uniform[i] = rng.Float64()
+	}
+	weights := make([]float64, len(samples))
+	for i := range weights {
+		weights[i] = 0.5 + float64(i%3)
+	}
+
+	tests := []struct {
+		name string
+		kde  *KDE
+	}{
+		{"Gaussian", New(samples, nil, Gaussian, Silverman)},
+		{"Epanechnikov", New(samples, nil, Epanechnikov, Scott)},
+		{"weighted", New(samples, weights, Gaussian, ISJ)},
+		{"bounded", New(uniform, nil, Gaussian, Silverman).SetBounds(0, 1)},
+	}
+	for _, tt := range tests {
+		lo := math.Max(tt.kde.Lower, -15)
+		hi := math.Min(tt.kde.Upper, 17)
+		if got := integrate(tt.kde, lo, hi); math.Abs(got-1) > 1e-4 {
+			t.Errorf("%s density integrates to %v, want 1", tt.name, got)
+		}
+	}
+}
+
+func TestReflectionAtBoundary(t *testing.T) {
+	// Exponential(1) draws have density 1 at the boundary zero; an
+	// uncorrected estimate puts half its mass there below zero
+	rng := rand.New(rand.NewPCG(4, 4))
+	samples := make([]float64, 5000)
+	for i := range samples {
+		samples[i] = rng.ExpFloat64()
+	}
+
+	free := New(samples, nil, Gaussian, Silverman)
+	bounded := New(samples, nil, Gaussian, Silverman).SetBounds(0, math.Inf(1))
+
+	if got := free.PDF(0); math.Abs(got-0.5) > 0.1 {
+		t.Errorf("uncorrected density at 0 = %v, want about 0.5", got)
+	}
+	if got := bounded.PDF(0); math.Abs(got-1) > 0.15 {
+		t.Errorf("reflected density at 0 = %v, want about 1", got)
+	}
+	if got := bounded.PDF(-0.1); got != 0 {
+		t.Errorf("reflected density below the bound = %v, want 0", got)
+	}
+	if got := integrate(bounded, 0, 20); math.Abs(got-1) > 1e-4 {
+		t.Errorf("reflected density integrates to %v over the support, want 1", got)
+	}
+	if got := integrate(free, 0, 20); got > 0.99 {
+		t.Errorf("uncorrected density integrates to %v over the support, want mass lost below 0", got)
+	}
+	if xs, _ := bounded.Grid(100); xs[0] != 0 {
+		t.Errorf("grid starts at %v, want the lower bound 0", xs[0])
+	}
+}
+
+func TestMode(t *testing.T) {
+	samples := normalSamples(5000, 2, 1, 5)
+	k := New(samples, nil, Gaussian, ISJ)
+	mode := k.Mode()
+	if math.Abs(mode-2) > 0.3 {
+		t.Errorf("mode of Normal(2, 1) draws = %v, want about 2", mode)
+	}
+
+	// The refined mode is at least as high as any point of a fine grid
+	xs, ys := k.Grid(5000)
+	best := 0
+	for i, y := range ys {
+		if y > ys[best] {
+			best = i
+		}
+	}
+	if math.Abs(mode-xs[best]) > 2*(xs[1]-xs[0]) || k.PDF(mode) < ys[best] {
+		t.Errorf("mode %v with density %v, fine grid maximum %v with density %v", mode, k.PDF(mode), xs[best], ys[best])
+	}
+
+	// A 70/30 mixture of Normal(-3, 1) and Normal(4, 1)
+	mixture := append(normalSamples(3500, -3, 1, 6), normalSamples(1500, 4, 1, 7)...)
+	if got := New(mixture, nil, Gaussian, ISJ).Mode(); math.Abs(got+3) > 0.2 {
+		t.Errorf("mode of the mixture = %v, want about -3", got)
+	}
+
+	// Weights can move the mode to the other component
+	weights := make([]float64, len(mixture))
+	for i := range weights {
+		weights[i] = 1
+		if i >= 3500 {
+			weights[i] = 5
+		}
+	}
+	if got := New(mixture, weights, Gaussian, ISJ).Mode(); math.Abs(got-4) > 0.2 {
+		t.Errorf("mode of the reweighted mixture = %v, want about 4", got)
+	}
+
+	if got := New(nil, nil, Gaussian, ISJ).Mode(); !math.IsNaN(got) {
+		t.Errorf("mode without samples = %v, want NaN", got)
+	}
+}
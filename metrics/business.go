@@ -2,6 +2,7 @@ package metrics
 
 import (
 	"math"
+	"math/rand/v2"
 
 	"github.com/MyVueCodeHub/myvue-bayes/distributions"
 	"gonum.org/v1/gonum/stat"
@@ -21,6 +22,9 @@ type MetricEstimate struct {
 // BusinessMetrics provides Bayesian estimates for common business metrics
 type BusinessMetrics struct {
 	DefaultPriors map[string]distributions.Prior
+
+	// Src, when set, drives all sampling so estimates are reproducible
+	Src rand.Source
 }
 
 // NewBusinessMetrics creates a new BusinessMetrics instance with sensible defaults
@@ -35,17 +39,29 @@ func NewBusinessMetrics() *BusinessMetrics {
 	}
 }
 
+// SetSource sets the random source used for all sampling
+func (bm *BusinessMetrics) SetSource(src rand.Source) {
+	bm.Src = src
+}
+
 // ConversionRate estimates conversion rate with uncertainty
 func (bm *BusinessMetrics) ConversionRate(successes, trials int) MetricEstimate {
 	prior := bm.DefaultPriors["conversion"]
 
-	// Create binary data
-	data := make([]float64, trials)
-	for i := 0; i < successes; i++ {
-		data[i] = 1.0
+	var posterior distributions.Posterior
+	if updater, ok := prior.(distributions.StatisticsUpdater); ok {
+		posterior = updater.UpdateStatistics(distributions.BinomialStatistics(successes, trials))
+	} else {
+		// Create binary data for priors that need raw observations
+		data := make([]float64, trials)
+		for i := 0; i < successes; i++ {
+			data[i] = 1.0
+		}
+		posterior = prior.Update(data)
+	}
+	if bm.Src != nil {
+		distributions.UseSource(posterior, bm.Src)
 	}
-
-	posterior := prior.Update(data)
 	samples := posterior.SampleN(10000)
 	summary := distributions.ComputeSummary(samples)
 
@@ -76,6 +92,7 @@ func (bm *BusinessMetrics) AverageOrderValue(orders []float64) MetricEstimate {
 
 	// Create posterior samples
 	normalPost := distributions.NewNormal(mean, stdDev/math.Sqrt(float64(len(orders))))
+	normalPost.SetSource(bm.Src)
 	samples := make([]float64, 10000)
 	for i := range samples {
 		samples[i] = math.Exp(normalPost.Sample())
@@ -210,6 +227,7 @@ func (bm *BusinessMetrics) RevenueProjection(
 		// Generate samples
 		samples := make([]float64, 10000)
 		dist := distributions.NewNormal(meanPred, predSE)
+		dist.SetSource(bm.Src)
 		for i := range samples {
 			samples[i] = math.Max(0, dist.Sample()) // Revenue can't be negative
 		}
@@ -0,0 +1,345 @@
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sort"
+
+	"github.com/MyVueCodeHub/myvue-bayes/distributions"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mathext"
+	"gonum.org/v1/gonum/optimize"
+)
+
+// Segment holds the conversions out of trials observed for one segment,
+// e.g. one country × device combination
+type Segment struct {
+	Name      string
+	Successes int
+	Trials    int
+}
+
+// HyperMethod selects how the population-level Beta hyperparameters of a
+// hierarchical model are learned
+type HyperMethod int
+
+const (
+	// EmpiricalBayes plugs in the hyperparameters that maximize the marginal
+	// likelihood of all segments
+	EmpiricalBayes HyperMethod = iota
+	// FullBayes integrates over the hyperparameters on a grid, using the
+	// weakly informative prior p(α, β) ∝ (α+β)^(-5/2)
+	FullBayes
+)
+
+// hyperGridSize is the number of grid points along each hyperparameter axis
+const hyperGridSize = 100
+
+// maxLogConcentration caps log(α+β); beyond it the segments are fully pooled
+const maxLogConcentration = 20
+
+// SegmentEstimate is the partially pooled conversion rate of one segment
+type SegmentEstimate struct {
+	Segment
+	// RawRate is the unpooled rate successes/trials
+	RawRate float64
+	// Estimate is the partially pooled posterior of the segment's rate
+	Estimate MetricEstimate
+	// Shrinkage is the weight the posterior mean places on the population
+	// mean, (α+β)/(α+β+trials): 0 means no pooling and 1 complete pooling
+	Shrinkage float64
+}
+
+// HierarchicalEstimate is the result of a hierarchical Beta-Binomial model
+type HierarchicalEstimate struct {
+	Method HyperMethod
+	// Alpha and Beta are the population hyperparameters; for FullBayes they
+	// are posterior means
+	Alpha float64
+	Beta  float64
+	// PopulationMean is the population conversion rate α/(α+β)
+	PopulationMean float64
+	// Concentration is α+β, the prior's worth in pseudo-trials
+	Concentration float64
+	// LogMarginalLikelihood is the log marginal likelihood of all segments at
+	// the hyperparameters, up to the binomial coefficients
+	LogMarginalLikelihood float64
+	// MeanShrinkage averages Shrinkage over segments
+	MeanShrinkage float64
+
+	Segments []SegmentEstimate
+}
+
+// HierarchicalConversionRates estimates the conversion rate of many segments
+// at once. Rather than giving every segment an independent Beta(1,1) prior,
+// the segments share a Beta(α, β) prior whose hyperparameters are learned
+// from all of them, so small segments are pulled towards the population rate
+// while large segments keep their own. It returns an error if fewer than two
+// segments are given or any counts are invalid.
+func (bm *BusinessMetrics) HierarchicalConversionRates(segments []Segment, method HyperMethod) (HierarchicalEstimate, error) {
+	if len(segments) < 2 {
+		return HierarchicalEstimate{}, fmt.Errorf("hierarchical model needs at least two segments, got %d", len(segments))
+	}
+	for _, s := range segments {
+		if s.Trials < 0 || s.Successes < 0 || s.Successes > s.Trials {
+			return HierarchicalEstimate{}, fmt.Errorf("segment %q has %d successes out of %d trials", s.Name, s.Successes, s.Trials)
+		}
+	}
+
+	mu, kappa := empiricalBayesHyper(segments)
+	if method == FullBayes {
+		return bm.fullBayesEstimate(segments, mu, kappa), nil
+	}
+	return bm.empiricalBayesEstimate(segments, mu, kappa), nil
+}
+
+// empiricalBayesEstimate pools the segments with the marginal likelihood
+// maximizing hyperparameters
+func (bm *BusinessMetrics) empiricalBayesEstimate(segments []Segment, mu, kappa float64) HierarchicalEstimate {
+	alpha, beta := mu*kappa, (1-mu)*kappa
+	result := HierarchicalEstimate{
+		Method:                EmpiricalBayes,
+		Alpha:                 alpha,
+		Beta:                  beta,
+		PopulationMean:        mu,
+		Concentration:         kappa,
+		LogMarginalLikelihood: logMarginalLikelihood(segments, alpha, beta),
+		Segments:              make([]SegmentEstimate, len(segments)),
+	}
+
+	for i, s := range segments {
+		posterior := distributions.NewBeta(alpha+float64(s.Successes), beta+float64(s.Trials-s.Successes))
+		posterior.SetSource(bm.Src)
+		result.Segments[i] = segmentEstimate(s, posterior.SampleN(10000), kappa/(kappa+float64(s.Trials)))
+		result.MeanShrinkage += result.Segments[i].Shrinkage
+	}
+	result.MeanShrinkage /= float64(len(segments))
+	return result
+}
+
+// fullBayesEstimate integrates over the hyperparameters on a grid in
+// (logit μ, log κ) centred on the empirical Bayes mode
+func (bm *BusinessMetrics) fullBayesEstimate(segments []Segment, mu, kappa float64) HierarchicalEstimate {
+	grid := newHyperGrid(segments, math.Log(mu/(1-mu)), math.Log(kappa))
+
+	result := HierarchicalEstimate{
+		Method:   FullBayes,
+		Segments: make([]SegmentEstimate, len(segments)),
+	}
+	for k, w := range grid.weights {
+		result.Alpha += w * grid.alpha[k]
+		result.Beta += w * grid.beta[k]
+	}
+	result.Concentration = result.Alpha + result.Beta
+	result.PopulationMean = result.Alpha / result.Concentration
+	result.LogMarginalLikelihood = logMarginalLikelihood(segments, result.Alpha, result.Beta)
+
+	// Draw hyperparameters from the grid, then each segment's rate given them
+	nSamples := 10000
+	draws := grid.sample(nSamples, bm.Src)
+
+	for i, s := range segments {
+		successes, failures := float64(s.Successes), float64(s.Trials-s.Successes)
+
+		shrinkage := 0.0
+		for k, w := range grid.weights {
+			kappa := grid.alpha[k] + grid.beta[k]
+			shrinkage += w * kappa / (kappa + float64(s.Trials))
+		}
+
+		samples := make([]float64, nSamples)
+		for j, k := range draws {
+			posterior := distributions.NewBeta(grid.alpha[k]+successes, grid.beta[k]+failures)
+			posterior.SetSource(bm.Src)
+			samples[j] = posterior.Sample()
+		}
+
+		result.Segments[i] = segmentEstimate(s, samples, shrinkage)
+		result.MeanShrinkage += shrinkage
+	}
+	result.MeanShrinkage /= float64(len(segments))
+	return result
+}
+
+// segmentEstimate summarizes the posterior samples of one segment
+func segmentEstimate(s Segment, samples []float64, shrinkage float64) SegmentEstimate {
+	summary := distributions.ComputeSummary(samples)
+	raw := math.NaN()
+	if s.Trials > 0 {
+		raw = float64(s.Successes) / float64(s.Trials)
+	}
+	return SegmentEstimate{
+		Segment: s,
+		RawRate: raw,
+		Estimate: MetricEstimate{
+			Mean:    summary.Mean,
+			Median:  summary.Median,
+			Mode:    summary.Mode,
+			CI95:    summary.CI95,
+			CI99:    summary.CI99,
+			Samples: samples,
+			Summary: summary,
+		},
+		Shrinkage: shrinkage,
+	}
+}
+
+// logMarginalLikelihood returns the Beta-Binomial log likelihood of the
+// segments given the hyperparameters, omitting the binomial coefficients
+func logMarginalLikelihood(segments []Segment, alpha, beta float64) float64 {
+	prior := mathext.Lbeta(alpha, beta)
+	total := 0.0
+	for _, s := range segments {
+		total += mathext.Lbeta(alpha+float64(s.Successes), beta+float64(s.Trials-s.Successes)) - prior
+	}
+	return total
+}
+
+// empiricalBayesHyper maximizes the marginal likelihood over the population
+// mean μ and concentration κ = α+β, starting from the method of moments
+func empiricalBayesHyper(segments []Segment) (mu, kappa float64) {
+	mu0, kappa0 := momentHyper(segments)
+
+	negLogLik := func(x []float64) float64 {
+		mu, kappa := hyperFromLogit(x[0], x[1])
+		return -logMarginalLikelihood(segments, mu*kappa, (1-mu)*kappa)
+	}
+	x0 := []float64{math.Log(mu0 / (1 - mu0)), math.Log(kappa0)}
+	result, err := optimize.Minimize(optimize.Problem{Func: negLogLik}, x0, nil, &optimize.NelderMead{})
+	if err != nil && result == nil {
+		return mu0, kappa0
+	}
+	return hyperFromLogit(result.X[0], result.X[1])
+}
+
+// momentHyper returns method of moments estimates of μ and κ
+func momentHyper(segments []Segment) (mu, kappa float64) {
+	successes, trials := 0.0, 0.0
+	for _, s := range segments {
+		successes += float64(s.Successes)
+		trials += float64(s.Trials)
+	}
+	mu = (successes + 0.5) / (trials + 1)
+
+	// Between-segment variance of the rates in excess of binomial noise
+	variance, noise, count := 0.0, 0.0, 0.0
+	for _, s := range segments {
+		if s.Trials == 0 {
+			continue
+		}
+		rate := float64(s.Successes) / float64(s.Trials)
+		variance += (rate - mu) * (rate - mu)
+		noise += mu * (1 - mu) / float64(s.Trials)
+		count++
+	}
+	kappa = 10
+	if count > 1 {
+		excess := (variance - noise) / count
+		if excess > 0 {
+			kappa = math.Max(mu*(1-mu)/excess-1, 0.1)
+		} else {
+			kappa = math.Exp(maxLogConcentration / 2)
+		}
+	}
+	return mu, kappa
+}
+
+// hyperFromLogit maps unconstrained coordinates to μ and κ, capping κ
+func hyperFromLogit(logitMu, logKappa float64) (mu, kappa float64) {
+	mu = 1 / (1 + math.Exp(-logitMu))
+	mu = math.Min(math.Max(mu, 1e-12), 1-1e-12)
+	return mu, math.Exp(math.Min(logKappa, maxLogConcentration))
+}
+
+// hyperGrid is a discretized posterior over the hyperparameters
+type hyperGrid struct {
+	alpha   []float64
+	beta    []float64
+	weights []float64
+}
+
+// newHyperGrid evaluates the hyperparameter posterior on a grid, first
+// coarsely to find where its mass lies and then finely over that region
+func newHyperGrid(segments []Segment, logitMu, logKappa float64) hyperGrid {
+	lo := [2]float64{logitMu - 4, math.Max(logKappa-10, -5)}
+	hi := [2]float64{logitMu + 4, math.Min(logKappa+10, maxLogConcentration)}
+	coarse := evaluateHyperGrid(segments, lo, hi)
+
+	// Keep the region whose log density is within 15 of the peak
+	peak := floats.Max(coarse.weights)
+	lo = [2]float64{math.Inf(1), math.Inf(1)}
+	hi = [2]float64{math.Inf(-1), math.Inf(-1)}
+	for k, lw := range coarse.weights {
+		if lw < peak-15 {
+			continue
+		}
+		mu := coarse.alpha[k] / (coarse.alpha[k] + coarse.beta[k])
+		point := [2]float64{math.Log(mu / (1 - mu)), math.Log(coarse.alpha[k] + coarse.beta[k])}
+		for d := range point {
+			lo[d] = math.Min(lo[d], point[d])
+			hi[d] = math.Max(hi[d], point[d])
+		}
+	}
+	// Widen by one coarse cell so the region's edges are not cut off
+	for d := range lo {
+		cell := (hi[d] - lo[d]) / (hyperGridSize - 1)
+		lo[d] -= cell + 0.05
+		hi[d] += cell + 0.05
+	}
+	hi[1] = math.Min(hi[1], maxLogConcentration)
+
+	grid := evaluateHyperGrid(segments, lo, hi)
+	peak = floats.Max(grid.weights)
+	for k, lw := range grid.weights {
+		grid.weights[k] = math.Exp(lw - peak)
+	}
+	floats.Scale(1/floats.Sum(grid.weights), grid.weights)
+	return grid
+}
+
+// sample draws n grid indices in proportion to their posterior weights
+func (g hyperGrid) sample(n int, src rand.Source) []int {
+	uniform := rand.Float64
+	if src != nil {
+		uniform = rand.New(src).Float64
+	}
+
+	cumulative := make([]float64, len(g.weights))
+	floats.CumSum(cumulative, g.weights)
+	total := cumulative[len(cumulative)-1]
+
+	draws := make([]int, n)
+	for j := range draws {
+		k := sort.SearchFloat64s(cumulative, uniform()*total)
+		draws[j] = min(k, len(cumulative)-1)
+	}
+	return draws
+}
+
+// evaluateHyperGrid returns the log posterior density of (logit μ, log κ) on
+// a regular grid over [lo, hi]. Under the prior p(α, β) ∝ (α+β)^(-5/2), the
+// change of variables contributes a Jacobian of αβ.
+func evaluateHyperGrid(segments []Segment, lo, hi [2]float64) hyperGrid {
+	n := hyperGridSize * hyperGridSize
+	grid := hyperGrid{
+		alpha:   make([]float64, 0, n),
+		beta:    make([]float64, 0, n),
+		weights: make([]float64, 0, n),
+	}
+	for i := 0; i < hyperGridSize; i++ {
+		u := lo[0] + (hi[0]-lo[0])*float64(i)/(hyperGridSize-1)
+		for j := 0; j < hyperGridSize; j++ {
+			v := lo[1] + (hi[1]-lo[1])*float64(j)/(hyperGridSize-1)
+			mu, kappa := hyperFromLogit(u, v)
+			alpha, beta := mu*kappa, (1-mu)*kappa
+
+			logPost := logMarginalLikelihood(segments, alpha, beta) -
+				2.5*math.Log(kappa) + math.Log(alpha) + math.Log(beta)
+			grid.alpha = append(grid.alpha, alpha)
+			grid.beta = append(grid.beta, beta)
+			grid.weights = append(grid.weights, logPost)
+		}
+	}
+	return grid
+}
@@ -0,0 +1,159 @@
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/MyVueCodeHub/myvue-bayes/distributions"
+)
+
+// ratTumours returns the rat tumour data of Gelman et al., Bayesian Data
+// Analysis (3rd ed.), Table 5.1: tumours out of rats in 70 historical
+// experiments followed by the current one
+func ratTumours() []Segment {
+	tumours := []int{
+		0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 1, 1, 1, 1, 1, 1, 1, 2, 2,
+		2, 2, 2, 2, 2, 2, 2, 1, 5, 2, 5, 3, 2, 7, 7, 3, 3, 2, 9, 10, 4, 4, 4, 4,
+		4, 4, 4, 10, 4, 4, 4, 5, 11, 12, 5, 5, 6, 5, 6, 6, 6, 6, 16, 15, 15, 9, 4,
+	}
+	rats := []int{
+		20, 20, 20, 20, 20, 20, 20, 19, 19, 19, 19, 18, 18, 17, 20, 20, 20, 20, 19, 19, 18, 18, 25, 24,
+		23, 20, 20, 20, 20, 20, 20, 10, 49, 19, 46, 27, 17, 49, 47, 20, 20, 13, 48, 50, 20, 20, 20, 20,
+		20, 20, 20, 48, 19, 19, 19, 22, 46, 49, 20, 20, 23, 19, 22, 20, 20, 20, 52, 46, 47, 24, 14,
+	}
+	segments := make([]Segment, len(tumours))
+	for i := range segments {
+		segments[i] = Segment{Name: fmt.Sprintf("experiment %d", i+1), Successes: tumours[i], Trials: rats[i]}
+	}
+	return segments
+}
+
+func TestHierarchicalRatTumours(t *testing.T) {
+	bm := NewBusinessMetrics()
+	bm.SetSource(distributions.NewSource(1))
+	segments := ratTumours()
+
+	// BDA reports posterior means E(α | y) ≈ 2.4 and E(β | y) ≈ 14.3 under
+	// the prior p(α, β) ∝ (α+β)^(-5/2); the marginal likelihood peaks nearby
+	for _, method := range []HyperMethod{EmpiricalBayes, FullBayes} {
+		result, err := bm.HierarchicalConversionRates(segments, method)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(result.Alpha-2.4) > 0.15 || math.Abs(result.Beta-14.3) > 0.6 {
+			t.Errorf("method %d: α = %v, β = %v, want about 2.4 and 14.3", method, result.Alpha, result.Beta)
+		}
+		if math.Abs(result.PopulationMean-0.143) > 0.005 {
+			t.Errorf("method %d: population mean = %v, want about 0.143", method, result.PopulationMean)
+		}
+
+		// The current experiment, 4 tumours in 14 rats, is pulled from its raw
+		// rate of 0.286 roughly halfway towards the population mean
+		current := result.Segments[70]
+		if current.Estimate.Mean < 0.19 || current.Estimate.Mean > 0.23 {
+			t.Errorf("method %d: current experiment mean = %v, want about 0.21", method, current.Estimate.Mean)
+		}
+		if current.Shrinkage < 0.45 || current.Shrinkage > 0.6 {
+			t.Errorf("method %d: current experiment shrinkage = %v, want about 0.53", method, current.Shrinkage)
+		}
+	}
+}
+
+func TestHierarchicalShrinksSmallSegments(t *testing.T) {
+	// 60 segments whose true rates come from Beta(20, 180): mean 0.1 and
+	// concentration 200, with trial counts spanning three orders of magnitude
+	rng := rand.New(rand.NewPCG(2, 2))
+	population := distributions.NewBeta(20, 180)
+	population.SetSource(distributions.NewSource(2))
+
+	segments := make([]Segment, 60)
+	truth := make([]float64, len(segments))
+	for i := range segments {
+		truth[i] = population.Sample()
+		trials := int(math.Pow(10, 1+3*float64(i)/float64(len(segments)-1)))
+		successes := 0
+		for range trials {
+			if rng.Float64() < truth[i] {
+				successes++
+			}
+		}
+		segments[i] = Segment{Name: fmt.Sprint(i), Successes: successes, Trials: trials}
+	}
+
+	bm := NewBusinessMetrics()
+	bm.SetSource(distributions.NewSource(3))
+	for _, method := range []HyperMethod{EmpiricalBayes, FullBayes} {
+		result, err := bm.HierarchicalConversionRates(segments, method)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(result.PopulationMean-0.1) > 0.015 {
+			t.Errorf("method %d: population mean = %v, want about 0.1", method, result.PopulationMean)
+		}
+		if result.Concentration < 50 || result.Concentration > 800 {
+			t.Errorf("method %d: concentration = %v, want about 200", method, result.Concentration)
+		}
+
+		var rawError, pooledError float64
+		for i, s := range result.Segments {
+			rawError += (s.RawRate - truth[i]) * (s.RawRate - truth[i])
+			pooledError += (s.Estimate.Mean - truth[i]) * (s.Estimate.Mean - truth[i])
+
+			// Shrinkage falls as segments grow
+			if i > 0 && s.Trials > result.Segments[i-1].Trials && s.Shrinkage > result.Segments[i-1].Shrinkage {
+				t.Errorf("method %d: segment with %d trials shrinks %v, more than %v with %d trials",
+					method, s.Trials, s.Shrinkage, result.Segments[i-1].Shrinkage, result.Segments[i-1].Trials)
+			}
+			// The estimate lies between the raw rate and the population mean
+			lo := math.Min(s.RawRate, result.PopulationMean)
+			hi := math.Max(s.RawRate, result.PopulationMean)
+			if slack := 0.01; s.Estimate.Mean < lo-slack || s.Estimate.Mean > hi+slack {
+				t.Errorf("method %d: segment %s estimate %v outside [%v, %v]", method, s.Name, s.Estimate.Mean, lo, hi)
+			}
+		}
+		if pooledError >= rawError {
+			t.Errorf("method %d: squared error of pooled estimates %v, not below raw rates %v", method, pooledError, rawError)
+		}
+
+		first, last := result.Segments[0], result.Segments[len(result.Segments)-1]
+		if first.Shrinkage < 0.8 || last.Shrinkage > 0.1 {
+			t.Errorf("method %d: shrinkage %v with %d trials and %v with %d trials",
+				method, first.Shrinkage, first.Trials, last.Shrinkage, last.Trials)
+		}
+	}
+}
+
+func TestHierarchicalEmpiricalBayesShrinkage(t *testing.T) {
+	bm := NewBusinessMetrics()
+	bm.SetSource(distributions.NewSource(4))
+	result, err := bm.HierarchicalConversionRates(ratTumours(), EmpiricalBayes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range result.Segments {
+		if want := result.Concentration / (result.Concentration + float64(s.Trials)); math.Abs(s.Shrinkage-want) > 1e-12 {
+			t.Errorf("segment %s: shrinkage %v, want κ/(κ+n) = %v", s.Name, s.Shrinkage, want)
+		}
+	}
+}
+
+func TestHierarchicalRejectsInvalidSegments(t *testing.T) {
+	bm := NewBusinessMetrics()
+	tests := []struct {
+		name     string
+		segments []Segment
+	}{
+		{"no segments", nil},
+		{"one segment", []Segment{{Name: "a", Successes: 1, Trials: 10}}},
+		{"negative trials", []Segment{{Name: "a", Successes: 0, Trials: -1}, {Name: "b", Successes: 1, Trials: 10}}},
+		{"negative successes", []Segment{{Name: "a", Successes: -1, Trials: 5}, {Name: "b", Successes: 1, Trials: 10}}},
+		{"more successes than trials", []Segment{{Name: "a", Successes: 6, Trials: 5}, {Name: "b", Successes: 1, Trials: 10}}},
+	}
+	for _, tt := range tests {
+		if _, err := bm.HierarchicalConversionRates(tt.segments, EmpiricalBayes); err == nil {
+			t.Errorf("%s: want error", tt.name)
+		}
+	}
+}
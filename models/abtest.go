@@ -1,20 +1,33 @@
 package models
 
 import (
-	"fmt"
-	"sort"
+	"math/rand/v2"
+	"time"
 
 	"github.com/MyVueCodeHub/myvue-bayes/distributions"
 )
 
-// ABTest represents a Bayesian A/B test
+// ABTest represents a Bayesian A/B test. Observations are folded into
+// sufficient statistics when the priors support it, so memory and update cost
+// do not grow with the number of visitors; ControlData and TreatmentData only
+// retain raw observations for priors that cannot be summarized.
 type ABTest struct {
 	ControlPrior   distributions.Prior
 	TreatmentPrior distributions.Prior
 	ControlData    []float64
 	TreatmentData  []float64
+	ControlStats   distributions.SufficientStatistics
+	TreatmentStats distributions.SufficientStatistics
 	ControlPost    distributions.Posterior
 	TreatmentPost  distributions.Posterior
+
+	// Src, when set, drives all posterior sampling so results are reproducible
+	Src rand.Source
+
+	// Policy decides when the test may stop and which variant to choose
+	Policy DecisionPolicy
+	// StartedAt is when the test began, used for the policy's MaxDuration
+	StartedAt time.Time
 }
 
 // NewABTest creates a new A/B test with default Beta(1,1) priors
@@ -22,36 +35,102 @@ func NewABTest() *ABTest {
 	return &ABTest{
 		ControlPrior:   distributions.NewBeta(1, 1),
 		TreatmentPrior: distributions.NewBeta(1, 1),
+		Policy:         DefaultDecisionPolicy(),
+		StartedAt:      time.Now(),
 	}
 }
 
-// NewABTestWithPriors creates a new A/B test with custom priors
+// NewABTestWithPriors creates a new A/B test with custom priors. For continuous
+// metrics such as revenue or latency, use distributions.NormalInverseGamma
+// priors so that the variance is learned from the data rather than assumed.
 func NewABTestWithPriors(controlPrior, treatmentPrior distributions.Prior) *ABTest {
 	return &ABTest{
 		ControlPrior:   controlPrior,
 		TreatmentPrior: treatmentPrior,
+		Policy:         DefaultDecisionPolicy(),
+		StartedAt:      time.Now(),
 	}
 }
 
 // AddControlData adds data for the control group
 func (ab *ABTest) AddControlData(data []float64) {
-	ab.ControlData = append(ab.ControlData, data...)
+	addObservations(ab.ControlPrior, &ab.ControlStats, &ab.ControlData, data)
 	ab.updatePosteriors()
 }
 
 // AddTreatmentData adds data for the treatment group
 func (ab *ABTest) AddTreatmentData(data []float64) {
-	ab.TreatmentData = append(ab.TreatmentData, data...)
+	addObservations(ab.TreatmentPrior, &ab.TreatmentStats, &ab.TreatmentData, data)
+	ab.updatePosteriors()
+}
+
+// AddControlStats adds aggregated observations for the control group. It
+// returns an error if the control prior cannot be updated from statistics.
+func (ab *ABTest) AddControlStats(stats distributions.SufficientStatistics) error {
+	if err := addStatistics(ab.ControlPrior, &ab.ControlStats, stats); err != nil {
+		return err
+	}
+	ab.updatePosteriors()
+	return nil
+}
+
+// AddTreatmentStats adds aggregated observations for the treatment group. It
+// returns an error if the treatment prior cannot be updated from statistics.
+func (ab *ABTest) AddTreatmentStats(stats distributions.SufficientStatistics) error {
+	if err := addStatistics(ab.TreatmentPrior, &ab.TreatmentStats, stats); err != nil {
+		return err
+	}
 	ab.updatePosteriors()
+	return nil
+}
+
+// AddControlCounts adds conversions out of visitors for the control group
+func (ab *ABTest) AddControlCounts(successes, trials int) error {
+	return ab.AddControlStats(distributions.BinomialStatistics(successes, trials))
+}
+
+// AddTreatmentCounts adds conversions out of visitors for the treatment group
+func (ab *ABTest) AddTreatmentCounts(successes, trials int) error {
+	return ab.AddTreatmentStats(distributions.BinomialStatistics(successes, trials))
+}
+
+// ControlCount returns the number of control observations
+func (ab *ABTest) ControlCount() int {
+	return int(ab.ControlStats.N) + len(ab.ControlData)
+}
+
+// TreatmentCount returns the number of treatment observations
+func (ab *ABTest) TreatmentCount() int {
+	return int(ab.TreatmentStats.N) + len(ab.TreatmentData)
+}
+
+// SetSource sets the random source used for all posterior sampling
+func (ab *ABTest) SetSource(src rand.Source) {
+	ab.Src = src
+	ab.seedPosteriors()
 }
 
 // updatePosteriors updates the posterior distributions
 func (ab *ABTest) updatePosteriors() {
-	if len(ab.ControlData) > 0 {
-		ab.ControlPost = ab.ControlPrior.Update(ab.ControlData)
+	if post := posteriorFrom(ab.ControlPrior, ab.ControlStats, ab.ControlData); post != nil {
+		ab.ControlPost = post
 	}
-	if len(ab.TreatmentData) > 0 {
-		ab.TreatmentPost = ab.TreatmentPrior.Update(ab.TreatmentData)
+	if post := posteriorFrom(ab.TreatmentPrior, ab.TreatmentStats, ab.TreatmentData); post != nil {
+		ab.TreatmentPost = post
+	}
+	ab.seedPosteriors()
+}
+
+// seedPosteriors applies the test's random source to its posteriors
+func (ab *ABTest) seedPosteriors() {
+	if ab.Src == nil {
+		return
+	}
+	if ab.ControlPost != nil {
+		distributions.UseSource(ab.ControlPost, ab.Src)
+	}
+	if ab.TreatmentPost != nil {
+		distributions.UseSource(ab.TreatmentPost, ab.Src)
 	}
 }
 
@@ -61,7 +140,11 @@ func (ab *ABTest) ProbabilityOfImprovement() float64 {
 		return 0.5
 	}
 
-	// Monte Carlo estimation
+	if p, ok := distributions.ProbabilityGreater(ab.ControlPost, ab.TreatmentPost); ok {
+		return p
+	}
+
+	// Monte Carlo estimation for posteriors without an exact comparison
 	nSamples := 10000
 	controlSamples := ab.ControlPost.SampleN(nSamples)
 	treatmentSamples := ab.TreatmentPost.SampleN(nSamples)
@@ -82,6 +165,14 @@ func (ab *ABTest) ExpectedLoss() (controlLoss, treatmentLoss float64) {
 		return 0, 0
 	}
 
+	// Exact expected losses when both posteriors belong to a comparable family
+	controlLoss, okControl := distributions.ExpectedExcess(ab.ControlPost, ab.TreatmentPost)
+	treatmentLoss, okTreatment := distributions.ExpectedExcess(ab.TreatmentPost, ab.ControlPost)
+	if okControl && okTreatment {
+		return controlLoss, treatmentLoss
+	}
+	controlLoss, treatmentLoss = 0, 0
+
 	nSamples := 10000
 	controlSamples := ab.ControlPost.SampleN(nSamples)
 	treatmentSamples := ab.TreatmentPost.SampleN(nSamples)
@@ -115,24 +206,11 @@ func (ab *ABTest) CredibleIntervalDifference(confidence float64) (lower, upper f
 		differences[i] = treatmentSamples[i] - controlSamples[i]
 	}
 
-	summary := distributions.ComputeSummary(differences)
-	alpha := (1 - confidence) / 2
-
-	if confidence == 0.95 {
-		return summary.CI95[0], summary.CI95[1]
-	} else if confidence == 0.99 {
-		return summary.CI99[0], summary.CI99[1]
-	}
-
-	// For other confidence levels, compute from samples
-	sortedDiff := make([]float64, len(differences))
-	copy(sortedDiff, differences)
-	sort.Float64s(sortedDiff)
-
-	lowerIdx := int(alpha * float64(len(sortedDiff)))
-	upperIdx := int((1 - alpha) * float64(len(sortedDiff)))
-
-	return sortedDiff[lowerIdx], sortedDiff[upperIdx]
+	summary := distributions.ComputeSummaryWithOptions(differences, distributions.SummaryOptions{
+		CredibleLevels: []float64{confidence},
+	})
+	interval := summary.Intervals[confidence]
+	return interval[0], interval[1]
 }
 
 // RelativeUplift calculates the relative uplift of treatment over control
@@ -159,52 +237,31 @@ func (ab *ABTest) RelativeUplift() (mean, lower, upper float64) {
 
 // Summary returns a human-readable summary of the A/B test results
 func (ab *ABTest) Summary() string {
+	return ab.Result(0.95).String()
+}
+
+// Decide applies the test's decision policy to the current posteriors
+func (ab *ABTest) Decide() Decision {
 	if ab.ControlPost == nil || ab.TreatmentPost == nil {
-		return "Insufficient data for analysis"
+		return Decision{Action: Continue, Reasons: []string{"insufficient data for analysis"}}
 	}
 
-	prob := ab.ProbabilityOfImprovement()
 	controlLoss, treatmentLoss := ab.ExpectedLoss()
-	lower, upper := ab.CredibleIntervalDifference(0.95)
-	upliftMean, upliftLower, upliftUpper := ab.RelativeUplift()
-
-	return fmt.Sprintf(`
-A/B Test Results:
-=================
-Control:    n=%d, mean=%.4f
-Treatment:  n=%d, mean=%.4f
-
-Probability of Improvement: %.2f%%
-Expected Loss:
-  - Control:   %.4f
-  - Treatment: %.4f
-
-95%% Credible Interval for Difference: [%.4f, %.4f]
-Relative Uplift: %.2f%% [%.2f%%, %.2f%%]
-
-Recommendation: %s
-`,
-		len(ab.ControlData),
-		ab.ControlPost.Mean(),
-		len(ab.TreatmentData),
-		ab.TreatmentPost.Mean(),
-		prob*100,
-		controlLoss,
-		treatmentLoss,
-		lower, upper,
-		upliftMean*100, upliftLower*100, upliftUpper*100,
-		ab.getRecommendation(prob, treatmentLoss),
-	)
-}
-
-func (ab *ABTest) getRecommendation(prob, treatmentLoss float64) string {
-	if prob > 0.95 && treatmentLoss < 0.01 {
-		return "Strong evidence favors treatment. Recommend implementation."
-	} else if prob > 0.80 {
-		return "Moderate evidence favors treatment. Consider implementation or continue testing."
-	} else if prob < 0.20 {
-		return "Evidence favors control. Treatment likely inferior."
-	} else {
-		return "Insufficient evidence to make a recommendation. Continue testing."
+	return ab.decide(ab.ProbabilityOfImprovement(), controlLoss, treatmentLoss)
+}
+
+func (ab *ABTest) decide(prob, controlLoss, treatmentLoss float64) Decision {
+	var elapsed time.Duration
+	if !ab.StartedAt.IsZero() {
+		elapsed = time.Since(ab.StartedAt)
 	}
+
+	return ab.Policy.Decide(Evidence{
+		ProbabilityOfImprovement: prob,
+		ControlLoss:              controlLoss,
+		TreatmentLoss:            treatmentLoss,
+		ControlCount:             ab.ControlCount(),
+		TreatmentCount:           ab.TreatmentCount(),
+		Elapsed:                  elapsed,
+	})
 }
@@ -0,0 +1,171 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Action is the outcome recommended by a decision policy
+type Action int
+
+const (
+	// Continue means the test should keep collecting data
+	Continue Action = iota
+	// ShipTreatment means the treatment should be rolled out
+	ShipTreatment
+	// KeepControl means the control should be kept
+	KeepControl
+)
+
+// String returns a stable identifier for the action
+func (a Action) String() string {
+	switch a {
+	case ShipTreatment:
+		return "ship_treatment"
+	case KeepControl:
+		return "keep_control"
+	default:
+		return "continue"
+	}
+}
+
+// MarshalText encodes the action as its stable identifier
+func (a Action) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalText decodes an action from its stable identifier
+func (a *Action) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "continue":
+		*a = Continue
+	case "ship_treatment":
+		*a = ShipTreatment
+	case "keep_control":
+		*a = KeepControl
+	default:
+		return fmt.Errorf("unknown action %q", text)
+	}
+	return nil
+}
+
+// Evidence is the state of a test that a decision policy acts on
+type Evidence struct {
+	ProbabilityOfImprovement float64
+	ControlLoss              float64 // expected loss of keeping control
+	TreatmentLoss            float64 // expected loss of shipping treatment
+	ControlCount             int
+	TreatmentCount           int
+	Elapsed                  time.Duration
+}
+
+// Decision is a structured recommendation together with the reasons behind it
+type Decision struct {
+	Action  Action   `json:"action"`
+	Reasons []string `json:"reasons"`
+}
+
+// String returns a human-readable description of the decision
+func (d Decision) String() string {
+	var headline string
+	switch d.Action {
+	case ShipTreatment:
+		headline = "Ship treatment"
+	case KeepControl:
+		headline = "Keep control"
+	default:
+		headline = "Continue testing"
+	}
+	if len(d.Reasons) == 0 {
+		return headline
+	}
+	return headline + ": " + strings.Join(d.Reasons, "; ")
+}
+
+// DecisionPolicy configures when a test may stop and which variant to choose.
+// A variant is chosen once enough data has been collected, its expected loss
+// is below the threshold of caring and, if configured, the probability that it
+// is better reaches the required level. When MaxDuration elapses without a
+// decision, the variant with the lower expected loss is chosen.
+type DecisionPolicy struct {
+	// ThresholdOfCaring is the expected loss, in metric units, small enough
+	// that choosing the worse variant would not matter
+	ThresholdOfCaring float64
+
+	// ShipProbability is the minimum P(treatment > control) needed to ship the
+	// treatment; zero disables the requirement
+	ShipProbability float64
+
+	// KeepProbability is the minimum P(control >= treatment) needed to keep the
+	// control; zero disables the requirement
+	KeepProbability float64
+
+	// MinSampleSize is the number of observations required in each arm before stopping
+	MinSampleSize int
+
+	// MaxDuration forces a decision once the test has run this long; zero means no limit
+	MaxDuration time.Duration
+}
+
+// DefaultDecisionPolicy returns a policy that ships at 95% probability and
+// keeps control at 80% probability, with a threshold of caring of 0.01
+func DefaultDecisionPolicy() DecisionPolicy {
+	return DecisionPolicy{
+		ThresholdOfCaring: 0.01,
+		ShipProbability:   0.95,
+		KeepProbability:   0.80,
+	}
+}
+
+// Decide applies the policy to the evidence
+func (p DecisionPolicy) Decide(e Evidence) Decision {
+	var reasons []string
+
+	enoughData := e.ControlCount >= p.MinSampleSize && e.TreatmentCount >= p.MinSampleSize
+	if !enoughData {
+		reasons = append(reasons, fmt.Sprintf("sample size %d/%d below minimum %d per arm",
+			e.ControlCount, e.TreatmentCount, p.MinSampleSize))
+	}
+
+	pControl := 1 - e.ProbabilityOfImprovement
+	if enoughData {
+		shipLoss := e.TreatmentLoss < p.ThresholdOfCaring
+		shipProb := e.ProbabilityOfImprovement >= p.ShipProbability
+		if shipLoss && shipProb {
+			return Decision{Action: ShipTreatment, Reasons: []string{
+				fmt.Sprintf("expected loss of treatment %.4g below threshold of caring %.4g",
+					e.TreatmentLoss, p.ThresholdOfCaring),
+				fmt.Sprintf("P(treatment > control) %.2f%% meets %.2f%%",
+					e.ProbabilityOfImprovement*100, p.ShipProbability*100),
+			}}
+		}
+
+		keepLoss := e.ControlLoss < p.ThresholdOfCaring
+		keepProb := pControl >= p.KeepProbability
+		if keepLoss && keepProb {
+			return Decision{Action: KeepControl, Reasons: []string{
+				fmt.Sprintf("expected loss of control %.4g below threshold of caring %.4g",
+					e.ControlLoss, p.ThresholdOfCaring),
+				fmt.Sprintf("P(control >= treatment) %.2f%% meets %.2f%%",
+					pControl*100, p.KeepProbability*100),
+			}}
+		}
+
+		reasons = append(reasons, fmt.Sprintf(
+			"expected losses %.4g (control) and %.4g (treatment) with P(treatment > control) %.2f%% do not meet the stopping criteria",
+			e.ControlLoss, e.TreatmentLoss, e.ProbabilityOfImprovement*100))
+	}
+
+	if p.MaxDuration > 0 && e.Elapsed >= p.MaxDuration {
+		reasons = append(reasons, fmt.Sprintf("maximum duration %s reached", p.MaxDuration))
+		if e.TreatmentLoss < e.ControlLoss {
+			reasons = append(reasons, "treatment has the lower expected loss")
+			return Decision{Action: ShipTreatment, Reasons: reasons}
+		}
+		reasons = append(reasons, "control has the lower expected loss")
+		return Decision{Action: KeepControl, Reasons: reasons}
+	}
+
+	return Decision{Action: Continue, Reasons: reasons}
+}
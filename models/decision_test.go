@@ -0,0 +1,163 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecisionPolicyDecide(t *testing.T) {
+	policy := DecisionPolicy{
+		ThresholdOfCaring: 0.01,
+		ShipProbability:   0.95,
+		KeepProbability:   0.8,
+		MinSampleSize:     100,
+		MaxDuration:       14 * 24 * time.Hour,
+	}
+	enough := Evidence{ControlCount: 100, TreatmentCount: 150, Elapsed: 24 * time.Hour}
+
+	tests := []struct {
+		name     string
+		policy   DecisionPolicy
+		evidence Evidence
+		want     Action
+		reason   string
+	}{
+		{
+			name:     "ship when loss and probability are met",
+			policy:   policy,
+			evidence: with(enough, 0.97, 0.02, 0.001),
+			want:     ShipTreatment,
+			reason:   "P(treatment > control) 97.00% meets 95.00%",
+		},
+		{
+			name:     "keep when loss and probability are met",
+			policy:   policy,
+			evidence: with(enough, 0.1, 0.001, 0.02),
+			want:     KeepControl,
+			reason:   "P(control >= treatment) 90.00% meets 80.00%",
+		},
+		{
+			name:     "continue when the loss is too high",
+			policy:   policy,
+			evidence: with(enough, 0.97, 0.05, 0.02),
+			want:     Continue,
+			reason:   "do not meet the stopping criteria",
+		},
+		{
+			name:     "continue when the probability is too low",
+			policy:   policy,
+			evidence: with(enough, 0.9, 0.02, 0.005),
+			want:     Continue,
+			reason:   "do not meet the stopping criteria",
+		},
+		{
+			name: "zero ship probability disables the requirement",
+			policy: DecisionPolicy{
+				ThresholdOfCaring: 0.01,
+				KeepProbability:   0.8,
+			},
+			evidence: with(enough, 0.6, 0.02, 0.005),
+			want:     ShipTreatment,
+			reason:   "expected loss of treatment 0.005 below threshold of caring 0.01",
+		},
+		{
+			name:     "continue below the minimum sample size",
+			policy:   policy,
+			evidence: Evidence{ProbabilityOfImprovement: 0.99, TreatmentLoss: 0.0001, ControlCount: 99, TreatmentCount: 500},
+			want:     Continue,
+			reason:   "sample size 99/500 below minimum 100 per arm",
+		},
+		{
+			name:   "max duration forces a decision below the minimum sample size",
+			policy: policy,
+			evidence: Evidence{
+				ProbabilityOfImprovement: 0.7,
+				ControlLoss:              0.03,
+				TreatmentLoss:            0.01,
+				ControlCount:             20,
+				TreatmentCount:           20,
+				Elapsed:                  15 * 24 * time.Hour,
+			},
+			want:   ShipTreatment,
+			reason: "maximum duration 336h0m0s reached",
+		},
+		{
+			name:     "max duration keeps control when its loss is lower",
+			policy:   policy,
+			evidence: with(Evidence{ControlCount: 500, TreatmentCount: 500, Elapsed: 14 * 24 * time.Hour}, 0.4, 0.02, 0.03),
+			want:     KeepControl,
+			reason:   "control has the lower expected loss",
+		},
+		{
+			name:     "zero max duration never forces a decision",
+			policy:   DecisionPolicy{ThresholdOfCaring: 0.01, ShipProbability: 0.95, MinSampleSize: 100},
+			evidence: Evidence{ProbabilityOfImprovement: 0.7, ControlLoss: 0.03, TreatmentLoss: 0.01, Elapsed: 1000 * time.Hour},
+			want:     Continue,
+		},
+	}
+	for _, tt := range tests {
+		d := tt.policy.Decide(tt.evidence)
+		if d.Action != tt.want {
+			t.Errorf("%s: action %v, want %v (reasons %q)", tt.name, d.Action, tt.want, d.Reasons)
+			continue
+		}
+		if tt.reason != "" && !strings.Contains(strings.Join(d.Reasons, "; "), tt.reason) {
+			t.Errorf("%s: reasons %q, want one containing %q", tt.name, d.Reasons, tt.reason)
+		}
+	}
+}
+
+// with returns the evidence with the given probability of improvement and losses
+func with(e Evidence, prob, controlLoss, treatmentLoss float64) Evidence {
+	e.ProbabilityOfImprovement = prob
+	e.ControlLoss = controlLoss
+	e.TreatmentLoss = treatmentLoss
+	return e
+}
+
+func TestDefaultDecisionPolicy(t *testing.T) {
+	p := DefaultDecisionPolicy()
+	if p.ThresholdOfCaring != 0.01 || p.ShipProbability != 0.95 || p.KeepProbability != 0.8 {
+		t.Errorf("default policy %+v", p)
+	}
+	if p.MinSampleSize != 0 || p.MaxDuration != 0 {
+		t.Errorf("default policy has a minimum sample size %d or maximum duration %v", p.MinSampleSize, p.MaxDuration)
+	}
+}
+
+func TestActionText(t *testing.T) {
+	for _, a := range []Action{Continue, ShipTreatment, KeepControl} {
+		data, err := json.Marshal(a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var back Action
+		if err := json.Unmarshal(data, &back); err != nil || back != a {
+			t.Errorf("%v encoded as %s decoded to %v, %v", a, data, back, err)
+		}
+	}
+
+	want := map[Action]string{Continue: `"continue"`, ShipTreatment: `"ship_treatment"`, KeepControl: `"keep_control"`}
+	for a, s := range want {
+		if data, _ := json.Marshal(a); string(data) != s {
+			t.Errorf("%v encoded as %s, want %s", a, data, s)
+		}
+	}
+
+	var a Action
+	if err := json.Unmarshal([]byte(`"rollback"`), &a); err == nil {
+		t.Error("decoding an unknown action: want error")
+	}
+}
+
+func TestDecisionString(t *testing.T) {
+	d := Decision{Action: KeepControl, Reasons: []string{"first", "second"}}
+	if got, want := d.String(), "Keep control: first; second"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if got := (Decision{Action: Continue}).String(); got != "Continue testing" {
+		t.Errorf("String() without reasons = %q, want %q", got, "Continue testing")
+	}
+}
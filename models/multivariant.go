@@ -0,0 +1,280 @@
+package models
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+
+	"github.com/MyVueCodeHub/myvue-bayes/distributions"
+)
+
+// Variant represents one arm of a multi-variant test. As in ABTest, Data only
+// retains raw observations for priors that cannot be summarized by Stats.
+type Variant struct {
+	Name      string
+	Prior     distributions.Prior
+	Data      []float64
+	Stats     distributions.SufficientStatistics
+	Posterior distributions.Posterior
+}
+
+// Count returns the number of observations for the variant
+func (v *Variant) Count() int {
+	return int(v.Stats.N) + len(v.Data)
+}
+
+// distribution returns the posterior, or the prior before any data arrives
+func (v *Variant) distribution() distributions.Distribution {
+	if v.Posterior != nil {
+		return v.Posterior
+	}
+	return v.Prior
+}
+
+// MultiVariantTest represents a Bayesian A/B/n test. The first variant is the control.
+type MultiVariantTest struct {
+	Variants []*Variant
+
+	// Src, when set, drives all posterior sampling so results are reproducible
+	Src rand.Source
+}
+
+// NewMultiVariantTest creates a test over the named variants with default
+// Beta(1,1) priors. The first name is the control; repeated names are skipped.
+func NewMultiVariantTest(names ...string) *MultiVariantTest {
+	mv := &MultiVariantTest{}
+	for _, name := range names {
+		mv.AddVariant(name, distributions.NewBeta(1, 1))
+	}
+	return mv
+}
+
+// AddVariant adds a named variant with its own prior. It returns an error if
+// a variant with the same name already exists.
+func (mv *MultiVariantTest) AddVariant(name string, prior distributions.Prior) error {
+	if _, ok := mv.Variant(name); ok {
+		return fmt.Errorf("variant %q already exists", name)
+	}
+	v := &Variant{Name: name, Prior: prior}
+	mv.Variants = append(mv.Variants, v)
+	mv.seed(v)
+	return nil
+}
+
+// SetSource sets the random source used for all posterior sampling
+func (mv *MultiVariantTest) SetSource(src rand.Source) {
+	mv.Src = src
+	for _, v := range mv.Variants {
+		mv.seed(v)
+	}
+}
+
+// seed sets the test's source on the distribution a variant is sampled from,
+// which is its prior until data arrives
+func (mv *MultiVariantTest) seed(v *Variant) {
+	if mv.Src != nil {
+		distributions.UseSource(v.distribution(), mv.Src)
+	}
+}
+
+// Control returns the control variant
+func (mv *MultiVariantTest) Control() *Variant {
+	if len(mv.Variants) == 0 {
+		return nil
+	}
+	return mv.Variants[0]
+}
+
+// Variant returns the variant with the given name
+func (mv *MultiVariantTest) Variant(name string) (*Variant, bool) {
+	for _, v := range mv.Variants {
+		if v.Name == name {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// AddData adds observations for the named variant
+func (mv *MultiVariantTest) AddData(name string, data []float64) error {
+	v, ok := mv.Variant(name)
+	if !ok {
+		return fmt.Errorf("unknown variant %q", name)
+	}
+
+	addObservations(v.Prior, &v.Stats, &v.Data, data)
+	mv.updatePosterior(v)
+	return nil
+}
+
+// AddStats adds aggregated observations for the named variant
+func (mv *MultiVariantTest) AddStats(name string, stats distributions.SufficientStatistics) error {
+	v, ok := mv.Variant(name)
+	if !ok {
+		return fmt.Errorf("unknown variant %q", name)
+	}
+
+	if err := addStatistics(v.Prior, &v.Stats, stats); err != nil {
+		return err
+	}
+	mv.updatePosterior(v)
+	return nil
+}
+
+// AddCounts adds conversions out of visitors for the named variant
+func (mv *MultiVariantTest) AddCounts(name string, successes, trials int) error {
+	return mv.AddStats(name, distributions.BinomialStatistics(successes, trials))
+}
+
+// updatePosterior recomputes a variant's posterior from its observations
+func (mv *MultiVariantTest) updatePosterior(v *Variant) {
+	v.Posterior = posteriorFrom(v.Prior, v.Stats, v.Data)
+	mv.seed(v)
+}
+
+// ProbabilityToBeBest returns, for each variant, the probability that it has
+// the highest mean among all variants
+func (mv *MultiVariantTest) ProbabilityToBeBest() map[string]float64 {
+	if len(mv.Variants) == 0 {
+		return map[string]float64{}
+	}
+
+	draws := mv.jointSamples(10000)
+	wins := make([]int, len(mv.Variants))
+	for i := range draws[0] {
+		wins[argmaxAt(draws, i)]++
+	}
+
+	probs := make(map[string]float64, len(mv.Variants))
+	for k, v := range mv.Variants {
+		probs[v.Name] = float64(wins[k]) / float64(len(draws[0]))
+	}
+	return probs
+}
+
+// ProbabilityOfImprovement returns, for each non-control variant, the
+// probability that it beats the control
+func (mv *MultiVariantTest) ProbabilityOfImprovement() map[string]float64 {
+	probs := make(map[string]float64, len(mv.Variants))
+	if len(mv.Variants) < 2 {
+		return probs
+	}
+
+	control := mv.Control()
+	for _, v := range mv.Variants[1:] {
+		probs[v.Name] = probabilityGreater(control.distribution(), v.distribution())
+	}
+	return probs
+}
+
+// ExpectedLoss returns, for each variant, the expected shortfall of choosing
+// it instead of the best variant: E[max_j θ_j - θ_i]
+func (mv *MultiVariantTest) ExpectedLoss() map[string]float64 {
+	if len(mv.Variants) == 0 {
+		return map[string]float64{}
+	}
+
+	draws := mv.jointSamples(10000)
+	nSamples := len(draws[0])
+	losses := make([]float64, len(mv.Variants))
+	for i := 0; i < nSamples; i++ {
+		best := draws[argmaxAt(draws, i)][i]
+		for k := range mv.Variants {
+			losses[k] += best - draws[k][i]
+		}
+	}
+
+	result := make(map[string]float64, len(mv.Variants))
+	for k, v := range mv.Variants {
+		result[v.Name] = losses[k] / float64(nSamples)
+	}
+	return result
+}
+
+// Summary returns a human-readable summary of the test results
+func (mv *MultiVariantTest) Summary() string {
+	if len(mv.Variants) < 2 {
+		return "At least two variants are required for analysis"
+	}
+	for _, v := range mv.Variants {
+		if v.Posterior == nil {
+			return "Insufficient data for analysis"
+		}
+	}
+
+	best := mv.ProbabilityToBeBest()
+	improvement := mv.ProbabilityOfImprovement()
+	losses := mv.ExpectedLoss()
+
+	var sb strings.Builder
+	sb.WriteString("\nMulti-Variant Test Results:\n")
+	sb.WriteString("===========================\n")
+	fmt.Fprintf(&sb, "%-16s %8s %10s %10s %12s %14s\n",
+		"Variant", "n", "Mean", "P(Best)", "P(>Control)", "Expected Loss")
+	for k, v := range mv.Variants {
+		vsControl := "-"
+		if k > 0 {
+			vsControl = fmt.Sprintf("%.2f%%", improvement[v.Name]*100)
+		}
+		fmt.Fprintf(&sb, "%-16s %8d %10.4f %9.2f%% %12s %14.4f\n",
+			v.Name, v.Count(), v.Posterior.Mean(), best[v.Name]*100, vsControl, losses[v.Name])
+	}
+	fmt.Fprintf(&sb, "\nRecommendation: %s\n", mv.getRecommendation(best, losses))
+
+	return sb.String()
+}
+
+func (mv *MultiVariantTest) getRecommendation(best, losses map[string]float64) string {
+	leader := mv.Variants[0]
+	for _, v := range mv.Variants[1:] {
+		if best[v.Name] > best[leader.Name] {
+			leader = v
+		}
+	}
+
+	if best[leader.Name] > 0.95 && losses[leader.Name] < 0.01 {
+		return fmt.Sprintf("Strong evidence favors %s. Recommend implementation.", leader.Name)
+	} else if best[leader.Name] > 0.80 {
+		return fmt.Sprintf("Moderate evidence favors %s. Consider implementation or continue testing.", leader.Name)
+	}
+	return "Insufficient evidence to make a recommendation. Continue testing."
+}
+
+// jointSamples draws nSamples values from each variant's distribution
+func (mv *MultiVariantTest) jointSamples(nSamples int) [][]float64 {
+	draws := make([][]float64, len(mv.Variants))
+	for k, v := range mv.Variants {
+		draws[k] = v.distribution().SampleN(nSamples)
+	}
+	return draws
+}
+
+// argmaxAt returns the variant index with the largest draw at position i
+func argmaxAt(draws [][]float64, i int) int {
+	best := 0
+	for k := 1; k < len(draws); k++ {
+		if draws[k][i] > draws[best][i] {
+			best = k
+		}
+	}
+	return best
+}
+
+// probabilityGreater returns P(b > a), exactly when possible and by Monte
+// Carlo otherwise
+func probabilityGreater(a, b distributions.Distribution) float64 {
+	if p, ok := distributions.ProbabilityGreater(a, b); ok {
+		return p
+	}
+
+	nSamples := 10000
+	aSamples := a.SampleN(nSamples)
+	bSamples := b.SampleN(nSamples)
+	wins := 0
+	for i := 0; i < nSamples; i++ {
+		if bSamples[i] > aSamples[i] {
+			wins++
+		}
+	}
+	return float64(wins) / float64(nSamples)
+}
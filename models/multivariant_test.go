@@ -0,0 +1,60 @@
+package models
+
+import (
+	"math"
+	"testing"
+
+	"github.com/MyVueCodeHub/myvue-bayes/distributions"
+)
+
+func TestMultiVariantRejectsDuplicateNames(t *testing.T) {
+	mv := NewMultiVariantTest("control", "a")
+	if err := mv.AddVariant("a", distributions.NewBeta(2, 2)); err == nil {
+		t.Error("AddVariant with a duplicate name: want error")
+	}
+	if err := mv.AddVariant("b", distributions.NewBeta(2, 2)); err != nil {
+		t.Errorf("AddVariant with a new name: %v", err)
+	}
+	if len(mv.Variants) != 3 {
+		t.Errorf("%d variants, want 3", len(mv.Variants))
+	}
+
+	// The first of repeated names is kept, so the control stays first
+	repeated := NewMultiVariantTest("control", "a", "control")
+	if len(repeated.Variants) != 2 || repeated.Control().Name != "control" {
+		t.Errorf("variants of a repeated name: %d, control %q", len(repeated.Variants), repeated.Control().Name)
+	}
+}
+
+func TestMultiVariantUnknownVariant(t *testing.T) {
+	mv := NewMultiVariantTest("control", "a")
+	if err := mv.AddCounts("missing", 1, 10); err == nil {
+		t.Error("AddCounts for an unknown variant: want error")
+	}
+	if err := mv.AddData("missing", []float64{1}); err == nil {
+		t.Error("AddData for an unknown variant: want error")
+	}
+}
+
+func TestMultiVariantFindsBestVariant(t *testing.T) {
+	mv := NewMultiVariantTest("control", "a", "b")
+	mv.SetSource(distributions.NewSource(1))
+	mv.AddCounts("control", 100, 1000)
+	mv.AddCounts("a", 105, 1000)
+	mv.AddCounts("b", 160, 1000)
+
+	best := mv.ProbabilityToBeBest()
+	total := 0.0
+	for _, p := range best {
+		total += p
+	}
+	if math.Abs(total-1) > 1e-9 {
+		t.Errorf("probabilities to be best sum to %v, want 1", total)
+	}
+	if best["b"] < 0.99 {
+		t.Errorf("P(b is best) = %v, want at least 0.99", best["b"])
+	}
+	if loss := mv.ExpectedLoss(); loss["b"] > loss["a"] || loss["b"] > loss["control"] {
+		t.Errorf("expected losses %v, want b lowest", loss)
+	}
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/MyVueCodeHub/myvue-bayes/distributions"
+)
+
+// addObservations folds raw observations into sufficient statistics when the
+// prior supports it, and retains them otherwise
+func addObservations(
+	prior distributions.Prior,
+	stats *distributions.SufficientStatistics,
+	raw *[]float64,
+	data []float64,
+) {
+	if updater, ok := prior.(distributions.StatisticsUpdater); ok {
+		*stats = stats.Add(updater.Statistics(data))
+		return
+	}
+	*raw = append(*raw, data...)
+}
+
+// addStatistics accumulates aggregated observations for a prior that supports them
+func addStatistics(
+	prior distributions.Prior,
+	stats *distributions.SufficientStatistics,
+	more distributions.SufficientStatistics,
+) error {
+	if _, ok := prior.(distributions.StatisticsUpdater); !ok {
+		return fmt.Errorf("prior %T cannot be updated from sufficient statistics", prior)
+	}
+	*stats = stats.Add(more)
+	return nil
+}
+
+// posteriorFrom computes the posterior from accumulated statistics or raw
+// data, returning nil when nothing has been observed
+func posteriorFrom(
+	prior distributions.Prior,
+	stats distributions.SufficientStatistics,
+	raw []float64,
+) distributions.Posterior {
+	if updater, ok := prior.(distributions.StatisticsUpdater); ok {
+		if stats.N > 0 {
+			return updater.UpdateStatistics(stats)
+		}
+		return nil
+	}
+	if len(raw) > 0 {
+		return prior.Update(raw)
+	}
+	return nil
+}
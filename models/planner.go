@@ -0,0 +1,226 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"time"
+
+	"github.com/MyVueCodeHub/myvue-bayes/distributions"
+)
+
+// TestPlanner simulates Bayesian A/B tests before launch to estimate how long
+// a test will run and how often it will reach the right decision. Each
+// simulation draws a true control conversion rate from the baseline prior,
+// applies the minimum detectable effect to obtain the treatment rate, feeds a
+// day of traffic at a time into an ABTest and applies the decision policy at
+// the end of every day.
+type TestPlanner struct {
+	// BaselinePrior is the belief about the control conversion rate
+	BaselinePrior *distributions.Beta
+	// AnalysisPrior is the prior of both arms in each simulated test; nil
+	// analyses with BaselinePrior
+	AnalysisPrior *distributions.Beta
+	// MinDetectableEffect is the relative uplift of the treatment, e.g. 0.05 for +5%
+	MinDetectableEffect float64
+	// DailyTraffic is the number of visitors per day, split evenly between arms
+	DailyTraffic int
+
+	// Policy is the decision rule applied at the end of each simulated day
+	Policy DecisionPolicy
+	// MaxDays ends a simulation that has not stopped; the variant with the
+	// lower expected loss is then chosen
+	MaxDays int
+	// Simulations is the number of simulated tests
+	Simulations int
+
+	// Src, when set, makes the simulations reproducible
+	Src rand.Source
+}
+
+// PlanResult summarizes the simulated tests
+type PlanResult struct {
+	ExpectedDays float64
+	MedianDays   float64
+	P90Days      float64
+
+	// ProbabilityCorrect is the fraction of tests that chose the truly better
+	// variant; when the variants are equal, keeping control counts as correct
+	ProbabilityCorrect float64
+	// ProbabilityShip is the fraction of tests that shipped the treatment
+	ProbabilityShip float64
+	// ProbabilityUndecided is the fraction of tests that reached MaxDays
+	// without the policy stopping them
+	ProbabilityUndecided float64
+
+	// ExpectedLossAtStop is the posterior expected loss of the chosen variant
+	ExpectedLossAtStop float64
+	// ExpectedRegret is the true conversion rate given up by the chosen variant
+	ExpectedRegret float64
+}
+
+// NewTestPlanner creates a planner with the default decision policy, a 60 day
+// limit and 1000 simulations
+func NewTestPlanner(baseline *distributions.Beta, mde float64, dailyTraffic int) *TestPlanner {
+	return &TestPlanner{
+		BaselinePrior:       baseline,
+		MinDetectableEffect: mde,
+		DailyTraffic:        dailyTraffic,
+		Policy:              DefaultDecisionPolicy(),
+		MaxDays:             60,
+		Simulations:         1000,
+	}
+}
+
+// SetSource sets the random source used by the simulations
+func (tp *TestPlanner) SetSource(src rand.Source) {
+	tp.Src = src
+}
+
+// Plan runs the simulations and summarizes them. It returns an error if the
+// planner's settings cannot describe a test.
+func (tp *TestPlanner) Plan() (PlanResult, error) {
+	if err := tp.validate(); err != nil {
+		return PlanResult{}, err
+	}
+
+	baseline := distributions.NewBeta(tp.BaselinePrior.Alpha, tp.BaselinePrior.Beta)
+	baseline.SetSource(tp.Src)
+
+	var result PlanResult
+	days := make([]float64, tp.Simulations)
+	for i := range days {
+		controlRate := baseline.Sample()
+		treatmentRate := math.Min(controlRate*(1+tp.MinDetectableEffect), 1)
+		outcome, err := tp.simulate(controlRate, treatmentRate)
+		if err != nil {
+			return PlanResult{}, err
+		}
+
+		days[i] = float64(outcome.days)
+		result.ExpectedDays += days[i]
+		if outcome.correct {
+			result.ProbabilityCorrect++
+		}
+		if outcome.action == ShipTreatment {
+			result.ProbabilityShip++
+		}
+		if outcome.undecided {
+			result.ProbabilityUndecided++
+		}
+		result.ExpectedLossAtStop += outcome.loss
+		result.ExpectedRegret += outcome.regret
+	}
+
+	n := float64(tp.Simulations)
+	result.ExpectedDays /= n
+	result.ProbabilityCorrect /= n
+	result.ProbabilityShip /= n
+	result.ProbabilityUndecided /= n
+	result.ExpectedLossAtStop /= n
+	result.ExpectedRegret /= n
+
+	slices.Sort(days)
+	result.MedianDays = days[int(0.5*(n-1))]
+	result.P90Days = days[int(0.9*(n-1))]
+	return result, nil
+}
+
+// validate checks that the planner's settings describe a test
+func (tp *TestPlanner) validate() error {
+	if tp.BaselinePrior == nil {
+		return fmt.Errorf("baseline prior is required")
+	}
+	if tp.DailyTraffic < 2 {
+		return fmt.Errorf("daily traffic %d must be at least 2 to reach both arms", tp.DailyTraffic)
+	}
+	if !(tp.MinDetectableEffect > -1) || math.IsInf(tp.MinDetectableEffect, 1) {
+		return fmt.Errorf("minimum detectable effect %v must be finite and greater than -1", tp.MinDetectableEffect)
+	}
+	if tp.MaxDays <= 0 {
+		return fmt.Errorf("max days %d must be positive", tp.MaxDays)
+	}
+	if tp.Simulations <= 0 {
+		return fmt.Errorf("simulations %d must be positive", tp.Simulations)
+	}
+	return nil
+}
+
+// simulatedTest is the outcome of a single simulated test
+type simulatedTest struct {
+	days      int
+	action    Action
+	undecided bool
+	correct   bool
+	loss      float64
+	regret    float64
+}
+
+// simulate runs one test with the given true conversion rates
+func (tp *TestPlanner) simulate(controlRate, treatmentRate float64) (simulatedTest, error) {
+	prior := tp.AnalysisPrior
+	if prior == nil {
+		prior = tp.BaselinePrior
+	}
+	ab := NewABTestWithPriors(
+		distributions.NewBeta(prior.Alpha, prior.Beta),
+		distributions.NewBeta(prior.Alpha, prior.Beta),
+	)
+	ab.Policy = tp.Policy
+	ab.SetSource(tp.Src)
+
+	perArm := tp.DailyTraffic / 2
+	control := distributions.NewBinomial(perArm, controlRate)
+	treatment := distributions.NewBinomial(tp.DailyTraffic-perArm, treatmentRate)
+	control.SetSource(tp.Src)
+	treatment.SetSource(tp.Src)
+
+	var out simulatedTest
+	var controlLoss, treatmentLoss float64
+	for day := 1; day <= tp.MaxDays; day++ {
+		if err := ab.AddControlCounts(int(control.Sample()), perArm); err != nil {
+			return simulatedTest{}, err
+		}
+		if err := ab.AddTreatmentCounts(int(treatment.Sample()), tp.DailyTraffic-perArm); err != nil {
+			return simulatedTest{}, err
+		}
+
+		prob := ab.ProbabilityOfImprovement()
+		controlLoss, treatmentLoss = ab.ExpectedLoss()
+		decision := tp.Policy.Decide(Evidence{
+			ProbabilityOfImprovement: prob,
+			ControlLoss:              controlLoss,
+			TreatmentLoss:            treatmentLoss,
+			ControlCount:             ab.ControlCount(),
+			TreatmentCount:           ab.TreatmentCount(),
+			Elapsed:                  time.Duration(day) * 24 * time.Hour,
+		})
+
+		out.days = day
+		out.action = decision.Action
+		if decision.Action != Continue {
+			break
+		}
+	}
+
+	if out.action == Continue {
+		out.undecided = true
+		out.action = KeepControl
+		if treatmentLoss < controlLoss {
+			out.action = ShipTreatment
+		}
+	}
+
+	best := math.Max(controlRate, treatmentRate)
+	if out.action == ShipTreatment {
+		out.correct = treatmentRate > controlRate
+		out.loss = treatmentLoss
+		out.regret = best - treatmentRate
+	} else {
+		out.correct = controlRate >= treatmentRate
+		out.loss = controlLoss
+		out.regret = best - controlRate
+	}
+	return out, nil
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/MyVueCodeHub/myvue-bayes/distributions"
+)
+
+// newTestPlanner returns a seeded planner for a 5% baseline conversion rate
+// whose policy stops once the expected loss is below 0.05 percentage points
+func newTestPlanner(mde float64, dailyTraffic int) *TestPlanner {
+	tp := NewTestPlanner(distributions.NewBeta(50, 950), mde, dailyTraffic)
+	tp.Policy.ThresholdOfCaring = 0.0005
+	tp.Simulations = 200
+	tp.SetSource(distributions.NewSource(1))
+	return tp
+}
+
+func plan(t *testing.T, tp *TestPlanner) PlanResult {
+	t.Helper()
+	result, err := tp.Plan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestPlanDurationFallsWithEffectAndTraffic(t *testing.T) {
+	small := plan(t, newTestPlanner(0.1, 2000))
+	large := plan(t, newTestPlanner(0.5, 2000))
+	busy := plan(t, newTestPlanner(0.1, 8000))
+
+	if large.ExpectedDays >= small.ExpectedDays {
+		t.Errorf("expected days with a 50%% effect = %v, not below %v with a 10%% effect",
+			large.ExpectedDays, small.ExpectedDays)
+	}
+	if busy.ExpectedDays >= small.ExpectedDays {
+		t.Errorf("expected days with 8000 visitors a day = %v, not below %v with 2000",
+			busy.ExpectedDays, small.ExpectedDays)
+	}
+	for _, r := range []PlanResult{small, large, busy} {
+		if !(1 <= r.MedianDays && r.MedianDays <= r.P90Days && r.P90Days <= 60) {
+			t.Errorf("median %v and 90th percentile %v days out of order", r.MedianDays, r.P90Days)
+		}
+	}
+}
+
+func TestPlanLargeEffectIsFoundReliably(t *testing.T) {
+	result := plan(t, newTestPlanner(0.5, 2000))
+	if result.ProbabilityCorrect < 0.95 {
+		t.Errorf("P(correct) for a 50%% effect = %v, want at least 0.95", result.ProbabilityCorrect)
+	}
+	if result.ProbabilityShip < 0.95 {
+		t.Errorf("P(ship) for a 50%% effect = %v, want at least 0.95", result.ProbabilityShip)
+	}
+	if result.ExpectedRegret > 0.001 {
+		t.Errorf("expected regret for a 50%% effect = %v, want below 0.001", result.ExpectedRegret)
+	}
+}
+
+func TestPlanIsReproducible(t *testing.T) {
+	first := plan(t, newTestPlanner(0.2, 2000))
+	second := plan(t, newTestPlanner(0.2, 2000))
+	if first != second {
+		t.Errorf("plans with the same source differ:\n%+v\n%+v", first, second)
+	}
+}
+
+func TestPlanRejectsInvalidSettings(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(tp *TestPlanner)
+	}{
+		{"nil baseline prior", func(tp *TestPlanner) { tp.BaselinePrior = nil }},
+		{"no daily traffic", func(tp *TestPlanner) { tp.DailyTraffic = 0 }},
+		{"one visitor a day", func(tp *TestPlanner) { tp.DailyTraffic = 1 }},
+		{"effect of -100%", func(tp *TestPlanner) { tp.MinDetectableEffect = -1 }},
+		{"no days", func(tp *TestPlanner) { tp.MaxDays = 0 }},
+		{"no simulations", func(tp *TestPlanner) { tp.Simulations = 0 }},
+	}
+	for _, tt := range tests {
+		tp := newTestPlanner(0.1, 2000)
+		tt.modify(tp)
+		if _, err := tp.Plan(); err == nil {
+			t.Errorf("%s: want error", tt.name)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package models
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ABTestResult is a structured, machine-readable snapshot of an A/B test. Its
+// JSON field names are stable and safe to depend on from dashboards and alerts.
+type ABTestResult struct {
+	// InsufficientData is set when either arm has no posterior yet; the
+	// remaining fields then hold their neutral values
+	InsufficientData bool `json:"insufficient_data"`
+
+	Control   VariantResult `json:"control"`
+	Treatment VariantResult `json:"treatment"`
+
+	ProbabilityOfImprovement float64 `json:"probability_of_improvement"`
+	ExpectedLossControl      float64 `json:"expected_loss_control"`
+	ExpectedLossTreatment    float64 `json:"expected_loss_treatment"`
+
+	// Difference holds credible intervals for treatment - control
+	Difference []Interval `json:"difference"`
+	// UpliftMean is the posterior mean of (treatment - control) / control
+	UpliftMean float64 `json:"uplift_mean"`
+	// Uplift holds credible intervals for the relative uplift
+	Uplift []Interval `json:"uplift"`
+
+	Decision Decision `json:"decision"`
+}
+
+// VariantResult describes the posterior of one arm
+type VariantResult struct {
+	Count int     `json:"count"`
+	Mean  float64 `json:"mean"`
+}
+
+// Interval is a credible interval at a given level
+type Interval struct {
+	Level float64 `json:"level"`
+	Lower float64 `json:"lower"`
+	Upper float64 `json:"upper"`
+}
+
+// Result computes a structured snapshot of the test with difference and
+// uplift intervals at each of the given credible levels, 0.95 by default
+func (ab *ABTest) Result(levels ...float64) ABTestResult {
+	if len(levels) == 0 {
+		levels = []float64{0.95}
+	}
+
+	result := ABTestResult{
+		Control:   VariantResult{Count: ab.ControlCount()},
+		Treatment: VariantResult{Count: ab.TreatmentCount()},
+	}
+	if ab.ControlPost == nil || ab.TreatmentPost == nil {
+		result.InsufficientData = true
+		result.ProbabilityOfImprovement = 0.5
+		result.Decision = ab.Decide()
+		return result
+	}
+
+	result.Control.Mean = ab.ControlPost.Mean()
+	result.Treatment.Mean = ab.TreatmentPost.Mean()
+	result.ProbabilityOfImprovement = ab.ProbabilityOfImprovement()
+	result.ExpectedLossControl, result.ExpectedLossTreatment = ab.ExpectedLoss()
+
+	nSamples := 10000
+	c := compareSamples(ab.ControlPost.SampleN(nSamples), ab.TreatmentPost.SampleN(nSamples), levels)
+	result.Difference = intervals(c.difference)
+	result.UpliftMean = c.upliftMean
+	result.Uplift = intervals(c.uplift)
+
+	result.Decision = ab.decide(
+		result.ProbabilityOfImprovement,
+		result.ExpectedLossControl,
+		result.ExpectedLossTreatment,
+	)
+	return result
+}
+
+// intervals converts intervals keyed by level into a slice ordered by level
+func intervals(byLevel map[float64][2]float64) []Interval {
+	out := make([]Interval, 0, len(byLevel))
+	for level, interval := range byLevel {
+		out = append(out, Interval{Level: level, Lower: interval[0], Upper: interval[1]})
+	}
+	slices.SortFunc(out, func(a, b Interval) int {
+		return cmp.Compare(a.Level, b.Level)
+	})
+	return out
+}
+
+// JSON returns the result encoded as indented JSON
+func (r ABTestResult) JSON() ([]byte, error) {
+	return json.MarshalIndent(r, "", "  ")
+}
+
+// String renders the result as a human-readable report
+func (r ABTestResult) String() string {
+	if r.InsufficientData {
+		return "Insufficient data for analysis"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `
+A/B Test Results:
+=================
+Control:    n=%d, mean=%.4f
+Treatment:  n=%d, mean=%.4f
+
+Probability of Improvement: %.2f%%
+Expected Loss:
+  - Control:   %.4f
+  - Treatment: %.4f
+
+`,
+		r.Control.Count, r.Control.Mean,
+		r.Treatment.Count, r.Treatment.Mean,
+		r.ProbabilityOfImprovement*100,
+		r.ExpectedLossControl,
+		r.ExpectedLossTreatment,
+	)
+
+	for _, interval := range r.Difference {
+		fmt.Fprintf(&b, "%g%% Credible Interval for Difference: [%.4f, %.4f]\n",
+			interval.Level*100, interval.Lower, interval.Upper)
+	}
+	fmt.Fprintf(&b, "Relative Uplift: %.2f%%", r.UpliftMean*100)
+	for _, interval := range r.Uplift {
+		fmt.Fprintf(&b, " [%.2f%%, %.2f%%]", interval.Lower*100, interval.Upper*100)
+		if len(r.Uplift) > 1 {
+			fmt.Fprintf(&b, " (%g%%)", interval.Level*100)
+		}
+	}
+	fmt.Fprintf(&b, "\n\nRecommendation: %s\n", r.Decision)
+
+	return b.String()
+}
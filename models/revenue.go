@@ -0,0 +1,341 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"github.com/MyVueCodeHub/myvue-bayes/distributions"
+)
+
+// RevenueVariant models revenue per visitor for one arm as the product of a
+// conversion probability and an average order value. Conversion has a Beta
+// prior. Order values are modelled as LogNormal: their logarithms are Normal
+// with unknown mean μ and variance σ², which have a Normal-Inverse-Gamma
+// prior, so the spread of order values is learned from the data and the
+// average order value is exp(μ + σ²/2).
+type RevenueVariant struct {
+	ConversionPrior *distributions.Beta
+	OrderValuePrior *distributions.NormalInverseGamma // prior on log order values
+
+	Visitors int
+	Orders   int
+	Revenue  float64
+	// LogOrderStats summarizes the logarithms of the order values
+	LogOrderStats distributions.SufficientStatistics
+
+	ConversionPost distributions.Posterior
+	OrderValuePost *distributions.NormalInverseGammaPosterior
+
+	// Src, when set, drives posterior sampling so results are reproducible
+	Src rand.Source
+}
+
+// newRevenueVariant creates a variant with the given priors
+func newRevenueVariant(conversion *distributions.Beta, orderValue *distributions.NormalInverseGamma) *RevenueVariant {
+	return &RevenueVariant{
+		ConversionPrior: conversion,
+		OrderValuePrior: orderValue,
+	}
+}
+
+// AddData adds per-visitor revenue, where zero means the visitor did not purchase
+func (v *RevenueVariant) AddData(revenue []float64) {
+	var logOrders []float64
+	total := 0.0
+	for _, r := range revenue {
+		if r > 0 {
+			logOrders = append(logOrders, math.Log(r))
+			total += r
+		}
+	}
+	v.AddStats(len(revenue), distributions.ComputeStatistics(logOrders), total)
+}
+
+// AddStats adds aggregated visitors, statistics of the logarithms of their
+// order values and the total revenue of those orders. The number of orders is
+// logOrders.N.
+func (v *RevenueVariant) AddStats(visitors int, logOrders distributions.SufficientStatistics, revenue float64) {
+	v.Visitors += visitors
+	v.Orders += int(logOrders.N)
+	v.Revenue += revenue
+	v.LogOrderStats = v.LogOrderStats.Add(logOrders)
+
+	v.ConversionPost = v.ConversionPrior.UpdateStatistics(
+		distributions.BinomialStatistics(v.Orders, v.Visitors),
+	)
+	v.OrderValuePost = v.OrderValuePrior.UpdateStatistics(v.LogOrderStats).(*distributions.NormalInverseGammaPosterior)
+	v.seedPosteriors()
+}
+
+// ready reports whether the variant has posteriors to analyze
+func (v *RevenueVariant) ready() bool {
+	return v.ConversionPost != nil && v.OrderValuePost != nil && v.Orders > 0
+}
+
+// SetSource sets the random source used for posterior sampling
+func (v *RevenueVariant) SetSource(src rand.Source) {
+	v.Src = src
+	v.seedPosteriors()
+}
+
+// seedPosteriors applies the variant's random source to its posteriors
+func (v *RevenueVariant) seedPosteriors() {
+	if v.Src == nil {
+		return
+	}
+	if v.ConversionPost != nil {
+		distributions.UseSource(v.ConversionPost, v.Src)
+	}
+	if v.OrderValuePost != nil {
+		v.OrderValuePost.SetSource(v.Src)
+	}
+}
+
+// ExpectedRevenuePerVisitor returns the posterior mean conversion rate times
+// the average order value at the posterior mean of μ and median of σ². The
+// posterior mean of exp(μ + σ²/2) itself is infinite, because the
+// inverse-gamma posterior of σ² has no exponential moments.
+func (v *RevenueVariant) ExpectedRevenuePerVisitor() float64 {
+	post := v.OrderValuePost
+	variance := post.VarianceMarginal().Quantile(0.5)
+	return v.ConversionPost.Mean() * math.Exp(post.Mu+variance/2)
+}
+
+// SampleRevenuePerVisitor draws n samples of revenue per visitor
+func (v *RevenueVariant) SampleRevenuePerVisitor(n int) []float64 {
+	post := v.OrderValuePost
+	conversions := v.ConversionPost.SampleN(n)
+	variances := post.VarianceMarginal().SampleN(n)
+
+	normal := rand.NormFloat64
+	if v.Src != nil {
+		normal = rand.New(v.Src).NormFloat64
+	}
+	samples := make([]float64, n)
+	for i := range samples {
+		// μ | σ² ~ Normal(Mu, σ²/Lambda)
+		mu := post.Mu + math.Sqrt(variances[i]/post.Lambda)*normal()
+		samples[i] = conversions[i] * math.Exp(mu+variances[i]/2)
+	}
+	return samples
+}
+
+// RevenueTest represents a Bayesian A/B test on revenue per visitor
+type RevenueTest struct {
+	Control   *RevenueVariant
+	Treatment *RevenueVariant
+
+	// Src, when set, drives all posterior sampling so results are reproducible
+	Src rand.Source
+
+	// Policy decides when the test may stop and which variant to choose. Its
+	// threshold of caring is in revenue per visitor.
+	Policy DecisionPolicy
+	// StartedAt is when the test began, used for the policy's MaxDuration
+	StartedAt time.Time
+}
+
+// NewRevenueTest creates a revenue test with Beta(1,1) conversion priors and
+// weak Normal-Inverse-Gamma(0, 0.01, 1, 1) priors on the log order values
+func NewRevenueTest() *RevenueTest {
+	return NewRevenueTestWithPriors(
+		distributions.NewBeta(1, 1),
+		distributions.NewNormalInverseGamma(0, 0.01, 1, 1),
+	)
+}
+
+// NewRevenueTestWithPriors creates a revenue test using the same conversion
+// prior and prior on the log order values for both arms
+func NewRevenueTestWithPriors(conversion *distributions.Beta, logOrderValue *distributions.NormalInverseGamma) *RevenueTest {
+	return &RevenueTest{
+		Control:   newRevenueVariant(conversion, logOrderValue),
+		Treatment: newRevenueVariant(conversion, logOrderValue),
+		Policy:    DefaultDecisionPolicy(),
+		StartedAt: time.Now(),
+	}
+}
+
+// SetSource sets the random source used for all posterior sampling
+func (rt *RevenueTest) SetSource(src rand.Source) {
+	rt.Src = src
+	rt.Control.SetSource(src)
+	rt.Treatment.SetSource(src)
+}
+
+// AddControlData adds per-visitor revenue for the control group
+func (rt *RevenueTest) AddControlData(revenue []float64) {
+	rt.Control.AddData(revenue)
+}
+
+// AddTreatmentData adds per-visitor revenue for the treatment group
+func (rt *RevenueTest) AddTreatmentData(revenue []float64) {
+	rt.Treatment.AddData(revenue)
+}
+
+// AddControlStats adds aggregated visitors, statistics of their log order
+// values and revenue for the control group
+func (rt *RevenueTest) AddControlStats(visitors int, logOrders distributions.SufficientStatistics, revenue float64) {
+	rt.Control.AddStats(visitors, logOrders, revenue)
+}
+
+// AddTreatmentStats adds aggregated visitors, statistics of their log order
+// values and revenue for the treatment group
+func (rt *RevenueTest) AddTreatmentStats(visitors int, logOrders distributions.SufficientStatistics, revenue float64) {
+	rt.Treatment.AddStats(visitors, logOrders, revenue)
+}
+
+// ProbabilityOfImprovement calculates P(treatment RPV > control RPV)
+func (rt *RevenueTest) ProbabilityOfImprovement() float64 {
+	if !rt.Control.ready() || !rt.Treatment.ready() {
+		return 0.5
+	}
+	return rt.compare().probability
+}
+
+// ExpectedLoss calculates the expected revenue-per-visitor loss of choosing each variant
+func (rt *RevenueTest) ExpectedLoss() (controlLoss, treatmentLoss float64) {
+	if !rt.Control.ready() || !rt.Treatment.ready() {
+		return 0, 0
+	}
+	c := rt.compare()
+	return c.controlLoss, c.treatmentLoss
+}
+
+// CredibleIntervalDifference returns the credible interval for the difference
+// in revenue per visitor, treatment - control
+func (rt *RevenueTest) CredibleIntervalDifference(confidence float64) (lower, upper float64) {
+	if !rt.Control.ready() || !rt.Treatment.ready() {
+		return 0, 0
+	}
+	c := rt.compare(confidence)
+	return c.difference[confidence][0], c.difference[confidence][1]
+}
+
+// RelativeUplift calculates the relative uplift in revenue per visitor
+func (rt *RevenueTest) RelativeUplift() (mean, lower, upper float64) {
+	if !rt.Control.ready() || !rt.Treatment.ready() {
+		return 0, 0, 0
+	}
+	c := rt.compare(0.95)
+	return c.upliftMean, c.uplift[0.95][0], c.uplift[0.95][1]
+}
+
+// Summary returns a human-readable summary of the revenue test results
+func (rt *RevenueTest) Summary() string {
+	if !rt.Control.ready() || !rt.Treatment.ready() {
+		return "Insufficient data for analysis"
+	}
+
+	c := rt.compare(0.95)
+
+	return fmt.Sprintf(`
+Revenue per Visitor Test Results:
+=================================
+Control:    visitors=%d, orders=%d, conversion=%.4f, RPV=%.4f
+Treatment:  visitors=%d, orders=%d, conversion=%.4f, RPV=%.4f
+
+Probability of Improvement: %.2f%%
+Expected Loss (revenue per visitor):
+  - Control:   %.4f
+  - Treatment: %.4f
+
+95%% Credible Interval for Difference: [%.4f, %.4f]
+Relative Uplift: %.2f%% [%.2f%%, %.2f%%]
+
+Recommendation: %s
+`,
+		rt.Control.Visitors, rt.Control.Orders,
+		rt.Control.ConversionPost.Mean(), rt.Control.ExpectedRevenuePerVisitor(),
+		rt.Treatment.Visitors, rt.Treatment.Orders,
+		rt.Treatment.ConversionPost.Mean(), rt.Treatment.ExpectedRevenuePerVisitor(),
+		c.probability*100,
+		c.controlLoss,
+		c.treatmentLoss,
+		c.difference[0.95][0], c.difference[0.95][1],
+		c.upliftMean*100, c.uplift[0.95][0]*100, c.uplift[0.95][1]*100,
+		rt.decide(c),
+	)
+}
+
+// Decide applies the test's decision policy to the current posteriors
+func (rt *RevenueTest) Decide() Decision {
+	if !rt.Control.ready() || !rt.Treatment.ready() {
+		return Decision{Action: Continue, Reasons: []string{"insufficient data for analysis"}}
+	}
+	return rt.decide(rt.compare())
+}
+
+func (rt *RevenueTest) decide(c sampleComparison) Decision {
+	var elapsed time.Duration
+	if !rt.StartedAt.IsZero() {
+		elapsed = time.Since(rt.StartedAt)
+	}
+
+	return rt.Policy.Decide(Evidence{
+		ProbabilityOfImprovement: c.probability,
+		ControlLoss:              c.controlLoss,
+		TreatmentLoss:            c.treatmentLoss,
+		ControlCount:             rt.Control.Visitors,
+		TreatmentCount:           rt.Treatment.Visitors,
+		Elapsed:                  elapsed,
+	})
+}
+
+// compare draws paired revenue-per-visitor samples and summarizes them
+func (rt *RevenueTest) compare(levels ...float64) sampleComparison {
+	nSamples := 10000
+	return compareSamples(
+		rt.Control.SampleRevenuePerVisitor(nSamples),
+		rt.Treatment.SampleRevenuePerVisitor(nSamples),
+		levels,
+	)
+}
+
+// sampleComparison summarizes paired posterior draws of control and treatment
+type sampleComparison struct {
+	probability   float64
+	controlLoss   float64
+	treatmentLoss float64
+	difference    map[float64][2]float64
+	upliftMean    float64
+	uplift        map[float64][2]float64
+}
+
+// compareSamples computes the probability of improvement, expected losses and
+// credible intervals for the difference and relative uplift at each level from
+// paired draws
+func compareSamples(control, treatment []float64, levels []float64) sampleComparison {
+	var c sampleComparison
+	n := len(control)
+	differences := make([]float64, n)
+	uplifts := make([]float64, 0, n)
+	wins := 0
+
+	for i := 0; i < n; i++ {
+		diff := treatment[i] - control[i]
+		differences[i] = diff
+		if diff > 0 {
+			wins++
+			c.controlLoss += diff
+		} else {
+			c.treatmentLoss -= diff
+		}
+		if control[i] > 0 {
+			uplifts = append(uplifts, diff/control[i])
+		}
+	}
+
+	c.probability = float64(wins) / float64(n)
+	c.controlLoss /= float64(n)
+	c.treatmentLoss /= float64(n)
+
+	opts := distributions.SummaryOptions{CredibleLevels: levels}
+	c.difference = distributions.ComputeSummaryWithOptions(differences, opts).Intervals
+	upliftSummary := distributions.ComputeSummaryWithOptions(uplifts, opts)
+	c.upliftMean = upliftSummary.Mean
+	c.uplift = upliftSummary.Intervals
+
+	return c
+}
@@ -0,0 +1,164 @@
+package ppl
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/MyVueCodeHub/myvue-bayes/autodiff"
+	"github.com/MyVueCodeHub/myvue-bayes/inference"
+)
+
+// Compiled is the joint log density of a model over the unconstrained values
+// of its random variables. Positive variables are sampled on the log scale
+// and unit-interval variables on the logit scale, with the log Jacobian of
+// each transform included, so any sampler can move freely over the reals.
+// A Compiled model reuses one tape and is not safe for concurrent use.
+type Compiled struct {
+	model *Model
+	tape  *autodiff.Tape
+}
+
+var _ inference.DifferentiableDensity = (*Compiled)(nil)
+
+// Compile checks the model and returns its joint log density. It returns an
+// error if the model has no random variables or was declared inconsistently.
+func (m *Model) Compile() (*Compiled, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	if len(m.random) == 0 {
+		return nil, fmt.Errorf("model has no random variables")
+	}
+	return &Compiled{model: m, tape: autodiff.NewTape()}, nil
+}
+
+// Dim returns the number of random variables
+func (c *Compiled) Dim() int {
+	return len(c.model.random)
+}
+
+// Names returns the names of the random variables followed by those of the
+// deterministic transforms, in the order Constrain reports them
+func (c *Compiled) Names() []string {
+	names := make([]string, 0, len(c.model.random)+len(c.model.deterministic))
+	for _, rv := range c.model.random {
+		names = append(names, rv.Name)
+	}
+	for _, d := range c.model.deterministic {
+		names = append(names, d.Name)
+	}
+	return names
+}
+
+// Initial returns an unconstrained starting point: zero for real variables,
+// one for positive variables and one half for unit-interval variables
+func (c *Compiled) Initial() []float64 {
+	return make([]float64, c.Dim())
+}
+
+// LogDensity returns the joint log density at the unconstrained point x
+func (c *Compiled) LogDensity(x []float64) float64 {
+	c.tape.Reset()
+	return c.evaluate(c.tape.Vars(x)).Value
+}
+
+// LogDensityGradient returns the joint log density at the unconstrained point
+// x and stores its gradient in grad
+func (c *Compiled) LogDensityGradient(x, grad []float64) float64 {
+	c.tape.Reset()
+	vars := c.tape.Vars(x)
+	out := c.evaluate(vars)
+	copy(grad, c.tape.Gradient(out, vars))
+	return out.Value
+}
+
+// evaluate records the joint log density of the unconstrained variables
+func (c *Compiled) evaluate(unconstrained []autodiff.Var) autodiff.Var {
+	e, logJacobian := c.newEnv(unconstrained)
+
+	total := logJacobian
+	for _, rv := range c.model.random {
+		total = total.Add(rv.Prior.logProb(e.random[rv.index], e))
+	}
+	for _, obs := range c.model.observed {
+		for i, y := range obs.data {
+			total = total.Add(obs.dist(i).logProb(c.tape.Const(y), e))
+		}
+	}
+	if math.IsNaN(total.Value) {
+		total.Value = math.Inf(-1)
+	}
+	return total
+}
+
+// newEnv maps unconstrained variables to their supports and returns the
+// evaluation environment with the summed log Jacobian of the transforms
+func (c *Compiled) newEnv(unconstrained []autodiff.Var) (*env, autodiff.Var) {
+	e := &env{
+		tape:          c.tape,
+		random:        make([]autodiff.Var, len(unconstrained)),
+		deterministic: make([]autodiff.Var, len(c.model.deterministic)),
+		evaluated:     make([]bool, len(c.model.deterministic)),
+	}
+
+	logJacobian := c.tape.Const(0)
+	for i, u := range unconstrained {
+		switch c.model.random[i].Prior.Support {
+		case Positive:
+			e.random[i] = autodiff.Exp(u)
+			logJacobian = logJacobian.Add(u)
+		case UnitInterval:
+			e.random[i] = autodiff.Logistic(u)
+			// log p(1-p) = -softplus(-u) - softplus(u)
+			logJacobian = logJacobian.Sub(autodiff.Softplus(u.Neg())).Sub(autodiff.Softplus(u))
+		default:
+			e.random[i] = u
+		}
+	}
+	return e, logJacobian
+}
+
+// Constrain maps an unconstrained point to the values of the random
+// variables followed by the deterministic transforms, as ordered by Names
+func (c *Compiled) Constrain(x []float64) []float64 {
+	c.tape.Reset()
+	e, _ := c.newEnv(c.tape.Vars(x))
+
+	values := make([]float64, 0, len(c.Names()))
+	for _, v := range e.random {
+		values = append(values, v.Value)
+	}
+	for _, d := range c.model.deterministic {
+		values = append(values, d.eval(e).Value)
+	}
+	return values
+}
+
+// ConstrainTrace maps every draw of a trace sampled from the compiled density
+// back to the model's variables. The parameters of the returned trace follow
+// Names, so it can be passed straight to TracePlot and ComputeSummary.
+func (c *Compiled) ConstrainTrace(t *inference.Trace) *inference.Trace {
+	dim := len(c.Names())
+	out := &inference.Trace{
+		Values:         make([][][]float64, dim),
+		AcceptanceRate: t.AcceptanceRate,
+		Divergences:    t.Divergences,
+		StepSize:       t.StepSize,
+	}
+	for p := range out.Values {
+		out.Values[p] = make([][]float64, t.NumChains())
+	}
+
+	x := make([]float64, t.Dim())
+	for chain := 0; chain < t.NumChains(); chain++ {
+		for draw := range t.Values[0][chain] {
+			for p := range x {
+				x[p] = t.Values[p][chain][draw]
+			}
+			for p, v := range c.Constrain(x) {
+				out.Values[p][chain] = append(out.Values[p][chain], v)
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,161 @@
+package ppl
+
+import (
+	"math"
+
+	"github.com/MyVueCodeHub/myvue-bayes/autodiff"
+	"github.com/MyVueCodeHub/myvue-bayes/distributions"
+)
+
+// Support is the set of values a distribution can take, which determines how
+// a random variable is mapped to the unconstrained space samplers work in
+type Support int
+
+const (
+	// Real values are sampled directly
+	Real Support = iota
+	// Positive values are sampled on the log scale
+	Positive
+	// UnitInterval values are sampled on the logit scale
+	UnitInterval
+)
+
+// Dist is a distribution whose parameters are nodes of a model
+type Dist struct {
+	Support Support
+	// discrete distributions may only describe observed data; samplers
+	// cannot move through the gaps between their values
+	discrete   bool
+	params     []Node
+	logDensity func(x autodiff.Var, params []autodiff.Var) autodiff.Var
+}
+
+// logProb evaluates the log density of x with the parameters in e
+func (d Dist) logProb(x autodiff.Var, e *env) autodiff.Var {
+	return d.logDensity(x, evalAll(d.params, e))
+}
+
+// Normal returns a Normal(mu, sigma) distribution
+func Normal(mu, sigma Node) Dist {
+	return Dist{Support: Real, params: []Node{mu, sigma}, logDensity: func(x autodiff.Var, p []autodiff.Var) autodiff.Var {
+		return autodiff.NormalLogPDF(x, p[0], p[1])
+	}}
+}
+
+// HalfNormal returns a Normal(0, sigma) distribution truncated to positive values
+func HalfNormal(sigma Node) Dist {
+	return Dist{Support: Positive, params: []Node{sigma}, logDensity: func(x autodiff.Var, p []autodiff.Var) autodiff.Var {
+		z := x.Div(p[0])
+		return autodiff.Square(z).Scale(-0.5).Sub(autodiff.Log(p[0])).Shift(math.Ln2 - 0.5*math.Log(2*math.Pi))
+	}}
+}
+
+// LogNormal returns a LogNormal(mu, sigma) distribution
+func LogNormal(mu, sigma Node) Dist {
+	return Dist{Support: Positive, params: []Node{mu, sigma}, logDensity: func(x autodiff.Var, p []autodiff.Var) autodiff.Var {
+		return autodiff.LogNormalLogPDF(x, p[0], p[1])
+	}}
+}
+
+// StudentT returns a Student's t distribution with location mu, scale sigma
+// and nu degrees of freedom
+func StudentT(mu, sigma, nu Node) Dist {
+	return Dist{Support: Real, params: []Node{mu, sigma, nu}, logDensity: func(x autodiff.Var, p []autodiff.Var) autodiff.Var {
+		return autodiff.StudentTLogPDF(x, p[0], p[1], p[2])
+	}}
+}
+
+// Beta returns a Beta(alpha, beta) distribution
+func Beta(alpha, beta Node) Dist {
+	return Dist{Support: UnitInterval, params: []Node{alpha, beta}, logDensity: func(x autodiff.Var, p []autodiff.Var) autodiff.Var {
+		return autodiff.BetaLogPDF(x, p[0], p[1])
+	}}
+}
+
+// Gamma returns a Gamma(shape, rate) distribution
+func Gamma(shape, rate Node) Dist {
+	return Dist{Support: Positive, params: []Node{shape, rate}, logDensity: func(x autodiff.Var, p []autodiff.Var) autodiff.Var {
+		return autodiff.GammaLogPDF(x, p[0], p[1])
+	}}
+}
+
+// InverseGamma returns an InverseGamma(shape, scale) distribution
+func InverseGamma(shape, scale Node) Dist {
+	return Dist{Support: Positive, params: []Node{shape, scale}, logDensity: func(x autodiff.Var, p []autodiff.Var) autodiff.Var {
+		return autodiff.InverseGammaLogPDF(x, p[0], p[1])
+	}}
+}
+
+// Exponential returns an Exponential(rate) distribution
+func Exponential(rate Node) Dist {
+	return Dist{Support: Positive, params: []Node{rate}, logDensity: func(x autodiff.Var, p []autodiff.Var) autodiff.Var {
+		return autodiff.ExponentialLogPDF(x, p[0])
+	}}
+}
+
+// Bernoulli returns a distribution over 0 and 1 with success probability p,
+// for observed data
+func Bernoulli(p Node) Dist {
+	return Binomial(1, p)
+}
+
+// Binomial returns the distribution of successes in n trials with success
+// probability p, for observed data
+func Binomial(n int, p Node) Dist {
+	return Dist{Support: Real, discrete: true, params: []Node{p}, logDensity: func(x autodiff.Var, params []autodiff.Var) autodiff.Var {
+		return autodiff.BinomialLogPMF(int(x.Value), n, params[0])
+	}}
+}
+
+// Poisson returns a Poisson distribution with the given rate, for observed data
+func Poisson(rate Node) Dist {
+	return Dist{Support: Real, discrete: true, params: []Node{rate}, logDensity: func(x autodiff.Var, p []autodiff.Var) autodiff.Var {
+		return autodiff.PoissonLogPMF(int(x.Value), p[0])
+	}}
+}
+
+// Prior converts a distribution with fixed parameters from the distributions
+// package, including conjugate priors and posteriors, into a model
+// distribution. Beta, Gamma, InverseGamma, Normal, StudentT and
+// Normal-Inverse-Gamma families keep their support and exact gradients.
+// Discrete distributions may only be observed; Random rejects them. Any
+// other distribution is treated as supported on the whole real line and
+// differentiated numerically.
+func Prior(d distributions.Distribution) Dist {
+	switch d := d.(type) {
+	case *distributions.Beta:
+		return Beta(Const(d.Alpha), Const(d.Beta))
+	case *distributions.BetaPosterior:
+		return Prior(d.Beta)
+	case *distributions.Gamma:
+		return Gamma(Const(d.Alpha), Const(d.Beta))
+	case *distributions.GammaPosterior:
+		return Prior(d.Gamma)
+	case *distributions.InverseGamma:
+		return InverseGamma(Const(d.Alpha), Const(d.Beta))
+	case *distributions.Normal:
+		return Normal(Const(d.Mu), Const(d.Sigma))
+	case *distributions.NormalConjugate:
+		return Prior(d.Normal)
+	case *distributions.NormalPosterior:
+		return Prior(d.Normal)
+	case *distributions.StudentT:
+		return StudentT(Const(d.Mu), Const(d.Sigma), Const(d.Nu))
+	case *distributions.NormalInverseGamma:
+		// The marginal of the mean is Student's t
+		sigma := math.Sqrt(d.Beta / (d.Alpha * d.Lambda))
+		return StudentT(Const(d.Mu), Const(sigma), Const(2*d.Alpha))
+	case *distributions.NormalInverseGammaPosterior:
+		return Prior(d.NormalInverseGamma)
+	case distributions.DiscreteDistribution:
+		return Dist{Support: Real, discrete: true, logDensity: func(x autodiff.Var, _ []autodiff.Var) autodiff.Var {
+			return autodiff.Custom(x, d.LogPDF(x.Value), 0)
+		}}
+	}
+
+	return Dist{Support: Real, logDensity: func(x autodiff.Var, _ []autodiff.Var) autodiff.Var {
+		h := 1e-6 * (math.Abs(x.Value) + 1)
+		derivative := (d.LogPDF(x.Value+h) - d.LogPDF(x.Value-h)) / (2 * h)
+		return autodiff.Custom(x, d.LogPDF(x.Value), derivative)
+	}}
+}
@@ -0,0 +1,157 @@
+// Package ppl is a small probabilistic modelling language. A Model declares
+// random variables with priors, deterministic transforms of them and observed
+// data, and compiles to a joint log density that any sampler in the inference
+// package can draw from.
+package ppl
+
+import (
+	"fmt"
+
+	"github.com/MyVueCodeHub/myvue-bayes/autodiff"
+)
+
+// Node is a quantity in a model: a random variable, a deterministic
+// transform or a constant
+type Node interface {
+	eval(e *env) autodiff.Var
+}
+
+// env holds the values of a model's nodes during one log density evaluation
+type env struct {
+	tape          *autodiff.Tape
+	random        []autodiff.Var
+	deterministic []autodiff.Var
+	evaluated     []bool
+}
+
+// constant is a fixed value
+type constant float64
+
+func (c constant) eval(e *env) autodiff.Var {
+	return e.tape.Const(float64(c))
+}
+
+// Const returns a node with a fixed value
+func Const(x float64) Node {
+	return constant(x)
+}
+
+// RandomVar is an unobserved random variable whose posterior is sampled
+type RandomVar struct {
+	Name  string
+	Prior Dist
+	index int
+}
+
+func (rv *RandomVar) eval(e *env) autodiff.Var {
+	return e.random[rv.index]
+}
+
+// Deterministic is a named function of other nodes. Its values are recorded
+// alongside the random variables when a trace is constrained.
+type Deterministic struct {
+	Name   string
+	fn     func(inputs []autodiff.Var) autodiff.Var
+	inputs []Node
+	index  int
+}
+
+func (d *Deterministic) eval(e *env) autodiff.Var {
+	if !e.evaluated[d.index] {
+		e.deterministic[d.index] = d.fn(evalAll(d.inputs, e))
+		e.evaluated[d.index] = true
+	}
+	return e.deterministic[d.index]
+}
+
+// transform is an anonymous function of other nodes
+type transform struct {
+	fn     func(inputs []autodiff.Var) autodiff.Var
+	inputs []Node
+}
+
+func (t transform) eval(e *env) autodiff.Var {
+	return t.fn(evalAll(t.inputs, e))
+}
+
+// Transform returns an unnamed function of the input nodes, written with
+// autodiff operations. Unlike a Deterministic it is not recorded in traces,
+// which suits per-observation quantities such as a regression's mean.
+func Transform(fn func(inputs []autodiff.Var) autodiff.Var, inputs ...Node) Node {
+	return transform{fn: fn, inputs: inputs}
+}
+
+// evalAll evaluates each of the nodes
+func evalAll(nodes []Node, e *env) []autodiff.Var {
+	values := make([]autodiff.Var, len(nodes))
+	for i, n := range nodes {
+		values[i] = n.eval(e)
+	}
+	return values
+}
+
+// observation is data assumed drawn from a distribution
+type observation struct {
+	dist func(i int) Dist
+	data []float64
+}
+
+// Model is a collection of random variables, deterministic transforms and
+// observed data. Declaration errors, such as duplicate names, are reported
+// by Compile.
+type Model struct {
+	random        []*RandomVar
+	deterministic []*Deterministic
+	observed      []observation
+	names         map[string]bool
+	err           error
+}
+
+// NewModel creates an empty model
+func NewModel() *Model {
+	return &Model{names: make(map[string]bool)}
+}
+
+// Random declares a random variable with the given prior, which must be
+// continuous; discrete distributions such as Binomial and Poisson can only be
+// observed
+func (m *Model) Random(name string, prior Dist) *RandomVar {
+	m.declare(name)
+	if prior.discrete && m.err == nil {
+		m.err = fmt.Errorf("random variable %q has a discrete prior", name)
+	}
+	rv := &RandomVar{Name: name, Prior: prior, index: len(m.random)}
+	m.random = append(m.random, rv)
+	return rv
+}
+
+// Deterministic declares a named transform of the input nodes, written with
+// autodiff operations so that it can be differentiated
+func (m *Model) Deterministic(name string, fn func(inputs []autodiff.Var) autodiff.Var, inputs ...Node) *Deterministic {
+	m.declare(name)
+	d := &Deterministic{Name: name, fn: fn, inputs: inputs, index: len(m.deterministic)}
+	m.deterministic = append(m.deterministic, d)
+	return d
+}
+
+// Observe declares data drawn independently from dist
+func (m *Model) Observe(dist Dist, data ...float64) {
+	m.observed = append(m.observed, observation{
+		dist: func(int) Dist { return dist },
+		data: data,
+	})
+}
+
+// ObserveEach declares data whose i-th value is drawn from dist(i), e.g.
+// for a regression with per-observation means
+func (m *Model) ObserveEach(data []float64, dist func(i int) Dist) {
+	m.observed = append(m.observed, observation{dist: dist, data: data})
+}
+
+// declare records a name, noting an error if it is already taken
+func (m *Model) declare(name string) {
+	if m.names[name] && m.err == nil {
+		m.err = fmt.Errorf("duplicate name %q", name)
+	}
+	m.names[name] = true
+}
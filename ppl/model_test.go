@@ -0,0 +1,162 @@
+package ppl
+
+import (
+	"math"
+	"testing"
+
+	"github.com/MyVueCodeHub/myvue-bayes/autodiff"
+	"github.com/MyVueCodeHub/myvue-bayes/distributions"
+	"github.com/MyVueCodeHub/myvue-bayes/inference"
+)
+
+// sample draws a constrained trace from a compiled model with a seeded NUTS
+func sample(t *testing.T, m *Model) (*Compiled, *inference.Trace) {
+	t.Helper()
+	model, err := m.Compile()
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+	nuts := inference.NewNUTS(model)
+	nuts.SetSource(distributions.NewSource(7))
+	trace, err := nuts.Sample(model.Initial())
+	if err != nil {
+		t.Fatalf("Sample: %v", err)
+	}
+	return model, model.ConstrainTrace(trace)
+}
+
+// checkMoments compares the draws of a parameter with an analytic posterior,
+// allowing five Monte Carlo standard errors for the mean
+func checkMoments(t *testing.T, trace *inference.Trace, p int, mean, variance float64) {
+	t.Helper()
+	summary := trace.Summary(p)
+	mcse := inference.MCSE(trace.Chains(p))
+	if math.Abs(summary.Mean-mean) > 5*mcse {
+		t.Errorf("mean = %.4f, want %.4f (MCSE %.4f)", summary.Mean, mean, mcse)
+	}
+	if math.Abs(summary.Variance/variance-1) > 0.15 {
+		t.Errorf("variance = %.5f, want %.5f", summary.Variance, variance)
+	}
+}
+
+func TestNormalMeanMatchesConjugatePosterior(t *testing.T) {
+	data := []float64{4.1, 5.3, 3.8, 6.0, 4.9, 5.5, 4.4, 5.1}
+	const priorSigma, sigma = 10.0, 2.0
+
+	m := NewModel()
+	mu := m.Random("mu", Normal(Const(0), Const(priorSigma)))
+	m.Observe(Normal(mu, Const(sigma)), data...)
+	_, trace := sample(t, m)
+
+	sum := 0.0
+	for _, y := range data {
+		sum += y
+	}
+	precision := 1/(priorSigma*priorSigma) + float64(len(data))/(sigma*sigma)
+	checkMoments(t, trace, 0, sum/(sigma*sigma)/precision, 1/precision)
+}
+
+func TestBetaBinomialMatchesConjugatePosterior(t *testing.T) {
+	tests := []struct {
+		name  string
+		prior Dist
+	}{
+		{"Beta", Beta(Const(2), Const(3))},
+		{"Prior", Prior(distributions.NewBeta(2, 3))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewModel()
+			p := m.Random("p", tt.prior)
+			m.Observe(Binomial(50, p), 17)
+			_, trace := sample(t, m)
+
+			posterior := distributions.NewBeta(2+17, 3+33)
+			checkMoments(t, trace, 0, posterior.Mean(), posterior.Variance())
+		})
+	}
+}
+
+func TestDeterministicIsRecorded(t *testing.T) {
+	m := NewModel()
+	x := m.Random("x", Normal(Const(1), Const(0.5)))
+	m.Deterministic("double", func(in []autodiff.Var) autodiff.Var {
+		return in[0].Scale(2)
+	}, x)
+	model, trace := sample(t, m)
+
+	if names := model.Names(); len(names) != 2 || names[1] != "double" {
+		t.Fatalf("Names() = %v, want [x double]", names)
+	}
+	for i, v := range trace.Samples(0) {
+		if got := trace.Samples(1)[i]; got != 2*v {
+			t.Fatalf("draw %d: double = %v, want %v", i, got, 2*v)
+		}
+	}
+}
+
+func TestPriorNormalInverseGammaIsStudentT(t *testing.T) {
+	nig := distributions.NewNormalInverseGamma(1, 2, 3, 4)
+	tape := autodiff.NewTape()
+	e := &env{tape: tape}
+	for _, x := range []float64{-2, 0, 1, 3.5} {
+		got := Prior(nig).logProb(tape.Var(x), e).Value
+		if want := nig.LogPDF(x); math.Abs(got-want) > 1e-10 {
+			t.Errorf("log density at %v = %v, want %v", x, got, want)
+		}
+	}
+	if got := Prior(nig).Support; got != Real {
+		t.Errorf("Support = %v, want Real", got)
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		build func(m *Model)
+	}{
+		{"empty model", func(m *Model) {}},
+		{"duplicate random", func(m *Model) {
+			m.Random("x", Normal(Const(0), Const(1)))
+			m.Random("x", Normal(Const(0), Const(1)))
+		}},
+		{"duplicate deterministic", func(m *Model) {
+			x := m.Random("x", Normal(Const(0), Const(1)))
+			m.Deterministic("x", func(in []autodiff.Var) autodiff.Var { return in[0] }, x)
+		}},
+		{"Poisson prior", func(m *Model) {
+			m.Random("k", Poisson(Const(3)))
+		}},
+		{"Bernoulli prior", func(m *Model) {
+			m.Random("k", Bernoulli(Const(0.5)))
+		}},
+		{"discrete Prior", func(m *Model) {
+			m.Random("k", Prior(distributions.NewPoisson(3)))
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewModel()
+			tt.build(m)
+			if _, err := m.Compile(); err == nil {
+				t.Error("Compile() succeeded, want error")
+			}
+		})
+	}
+}
+
+func TestDiscretePriorCanBeObserved(t *testing.T) {
+	m := NewModel()
+	m.Random("x", Normal(Const(0), Const(1)))
+	m.Observe(Prior(distributions.NewPoisson(3)), 2, 4)
+	model, err := m.Compile()
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+
+	poisson := distributions.NewPoisson(3)
+	want := distributions.NewNormal(0, 1).LogPDF(0.5) + poisson.LogPDF(2) + poisson.LogPDF(4)
+	if got := model.LogDensity([]float64{0.5}); math.Abs(got-want) > 1e-10 {
+		t.Errorf("LogDensity = %v, want %v", got, want)
+	}
+}
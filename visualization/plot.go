@@ -5,6 +5,7 @@ import (
 	"image/color"
 
 	"github.com/MyVueCodeHub/myvue-bayes/distributions"
+	"github.com/MyVueCodeHub/myvue-bayes/kde"
 	"gonum.org/v1/plot"
 	"gonum.org/v1/plot/plotter"
 	"gonum.org/v1/plot/vg"
@@ -103,13 +104,10 @@ func (bp *BayesianPlotter) CredibleIntervalPlot(
 	h.Normalize(1)
 
 	// Calculate credible interval
-	summary := distributions.ComputeSummary(samples)
-	var lower, upper float64
-	if credibleLevel == 0.95 {
-		lower, upper = summary.CI95[0], summary.CI95[1]
-	} else {
-		lower, upper = summary.CI99[0], summary.CI99[1]
-	}
+	summary := distributions.ComputeSummaryWithOptions(samples, distributions.SummaryOptions{
+		CredibleLevels: []float64{credibleLevel},
+	})
+	lower, upper := summary.Intervals[credibleLevel][0], summary.Intervals[credibleLevel][1]
 
 	// Add vertical lines for credible interval
 	lowerLine, err := plotter.NewLine(plotter.XYs{
@@ -139,6 +137,31 @@ func (bp *BayesianPlotter) CredibleIntervalPlot(
 	return nil
 }
 
+// DensityPlot draws a kernel density estimate of the samples. It returns an
+// error if there are no samples.
+func (bp *BayesianPlotter) DensityPlot(samples []float64, label string) error {
+	if len(samples) == 0 {
+		return fmt.Errorf("density plot %q has no samples", label)
+	}
+	xs, ys := kde.New(samples, nil, kde.Gaussian, kde.ISJ).Grid(512)
+
+	line, err := plotter.NewLine(plotter.XYs{})
+	if err != nil {
+		return err
+	}
+	for i := range xs {
+		line.XYs = append(line.XYs, plotter.XY{X: xs[i], Y: ys[i]})
+	}
+	line.Width = vg.Points(2)
+
+	bp.plot.Add(line)
+	bp.plot.Legend.Add(label, line)
+	bp.plot.X.Label.Text = "Value"
+	bp.plot.Y.Label.Text = "Density"
+
+	return nil
+}
+
 // TracePlot creates trace plots for MCMC diagnostics
 func (bp *BayesianPlotter) TracePlot(chains [][]float64) error {
 	for i, chain := range chains {